@@ -2,19 +2,24 @@ package golifx
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
-	"strings"
 	"time"
+
+	"github.com/2tvenom/golifx/protocol"
 )
 
 type (
 	Bulb struct {
+		client          *Client
 		hardwareAddress uint64
 		ipAddress       net.Addr
 		port            uint32
+		iface           string
+		link            string
 		label           string
 		powerState      bool
 		stateHostInfo   *BulbSignalInfo
@@ -72,304 +77,704 @@ type (
 )
 
 var (
-	// ErrNoResponse is returned when an acknowledgement is not received
+	// ErrNoResponse matches, with errors.Is, the *Error of a request whose
+	// acknowledgement was not received in time
 	ErrNoResponse = errors.New("No acknowledgement response")
 	// ErrIncorrectResponseType is returned on receiving an unexpected response
 	ErrIncorrectResponseType = errors.New("Incorrect response type")
+	// ErrInvalidMAC is returned by ParseMAC for addresses not 6 bytes long
+	ErrInvalidMAC = errors.New("MAC address must be 6 bytes long")
+	// ErrHardwareAddressMismatch is returned by Verify, wrapped in an *Error,
+	// when the device at the bulb IP reports another MAC address
+	ErrHardwareAddressMismatch = errors.New("Device has another MAC address")
+	// ErrUnknownAddress is returned by Verify for bulbs without an IP address
+	ErrUnknownAddress = errors.New("Bulb IP address is unknown")
 )
 
-func (b *Bulb) sendAndReceive(msg *message) (*message, error) {
-	return b.sendAndReceiveDead(msg, _DEFAULT_MAX_DEAD_LINE)
+// sendAndReceive sends the message to the bulb and returns the first reply,
+// resending it according to the retry policy. Failures are returned as *Error.
+func (b *Bulb) sendAndReceive(ctx context.Context, msg *message, expected uint16) (*message, error) {
+	responses, err := b.sendAndCollect(ctx, msg, expected, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return responses[0], nil
 }
 
-func (b *Bulb) sendAndReceiveDead(msg *message, deadLine time.Duration) (*message, error) {
-	msg.target = b.hardwareAddress
-	messages, err := conn.sendAndReceiveDead(msg, deadLine)
+// sendAndCollect sends the message to the bulb like sendAndReceive, then
+// gathers further replies of the expected type until count replies are
+// collected (zero means no limit) or the bulb goes quiet. Replies are
+// returned in the order they arrived, duplicates caused by resending are
+// dropped.
+func (b *Bulb) sendAndCollect(ctx context.Context, msg *message, expected uint16, count int) ([]*message, error) {
+	client := b.getClient()
+	policy := client.retryPolicyFor(ctx)
+
+	msg.source = client.source
+
+	// Tagged messages are answered by whichever device gets them
+	if !msg.tagged {
+		msg.target = b.hardwareAddress
+	}
+
+	req, err := client.conn.register(msg)
 
 	if err != nil {
+		return nil, b.newError(msg, expected, 0, err)
+	}
+
+	defer client.conn.unregister(msg, req)
+
+	raw := msg.ReadRaw()
+
+	// Every attempt goes to the bulb address, a single broadcast follows
+	// when all of them failed in case the bulb got a new address. Bulbs
+	// without a known address get the broadcast only. Tagged messages are
+	// never broadcast to a bulb with an address, any device would answer.
+	attempts, broadcast := 1, true
+
+	if b.udpAddr() != nil {
+		attempts, broadcast = policy.attempts(), !msg.tagged
+
+		if broadcast {
+			attempts++
+		}
+	}
+
+	timeout := policy.sendTimeout(client.deadline, attempts)
+
+	// Without a timeout per send the client deadline bounds the attempts
+	sendCtx := ctx
+
+	if policy.Timeout <= 0 {
+		var cancel context.CancelFunc
+		sendCtx, cancel = context.WithTimeout(ctx, client.deadline)
+		defer cancel()
+	}
+
+	attempt := 0
+
+	for attempt < attempts {
+		if err = sleep(sendCtx, policy.backoff(attempt)); err != nil {
+			break
+		}
+
+		attempt++
+
+		var response *message
+		response, err = b.attempt(sendCtx, client, req, raw, broadcast && attempt == attempts, timeout)
+
+		if err == nil {
+			first, err := b.checkResponse(msg, expected, attempt, b.remember(response))
+
+			if err != nil {
+				return nil, err
+			}
+
+			return b.collectRest(ctx, client, req, policy.timeout(client.deadline), first, count)
+		}
+
+		if err == ErrSuperseded || sendCtx.Err() != nil {
+			break
+		}
+	}
+
+	// Errors of ctx are returned as they are, running out of the client
+	// deadline is a timeout
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	} else if sendCtx.Err() != nil {
+		err = ErrTimeout
+	}
+
+	return nil, b.newError(msg, expected, attempt, err)
+}
+
+// collectRest gathers the replies following the first one
+func (b *Bulb) collectRest(ctx context.Context, client *Client, req *request, timeout time.Duration, first *message, count int) ([]*message, error) {
+	responses := []*message{first}
+
+	if count == 1 {
+		return responses, nil
+	}
+
+	remaining := 0
+
+	if count > 1 {
+		remaining = count - 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		messages, err := client.conn.collect(ctx, req, remaining, _DEFAULT_QUIET_PERIOD)
+
+		if err == ErrTimeout {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range messages {
+			if m._type == first._type && !containsMessage(responses, m) {
+				responses = append(responses, b.remember(m))
+			}
+		}
+
+		if count == 0 || len(messages) < remaining || len(responses) >= count {
+			break
+		}
+
+		// Duplicates and other messages were dropped, wait for the rest
+		remaining = count - len(responses)
+	}
+
+	return responses, nil
+}
+
+// checkResponse returns the reply if it has the expected type. The reply to
+// a tagged message has to come from the bulb as any device answers it.
+func (b *Bulb) checkResponse(msg *message, expected uint16, attempts int, response *message) (*message, error) {
+	var err error
+
+	switch {
+	case response._type == protocol.TypeDeviceStateUnhandled:
+		err = &UnsupportedMessageError{Bulb: b, MessageType: msg._type}
+	case response._type != expected:
+		err = ErrIncorrectResponseType
+	case msg.tagged && response.target != b.hardwareAddress:
+		err = ErrHardwareAddressMismatch
+	default:
+		return response, nil
+	}
+
+	e := b.newError(msg, expected, attempts, err)
+
+	if response._type != expected {
+		e.ReceivedType = response._type
+	}
+
+	return nil, e
+}
+
+// attempt writes the message to the bulb address and waits for a reply.
+// The last attempt broadcasts the message instead, it reaches a bulb that
+// stopped answering at its address (e.g. it got a new DHCP lease).
+func (b *Bulb) attempt(ctx context.Context, client *Client, req *request, raw []byte, broadcast bool, timeout time.Duration) (*message, error) {
+	link := b.linkKey()
+
+	if addr := b.udpAddr(); addr != nil && !broadcast {
+		return b.attemptAddr(ctx, client, req, raw, link, addr, timeout)
+	}
+
+	if err := client.limiter.waitBroadcast(ctx); err != nil {
+		return nil, err
+	}
+
+	return b.attemptAddr(ctx, client, req, raw, link, client.conn.broadcastAddrFor(link), timeout)
+}
+
+// attemptAddr waits for the bulb rate limit, writes the message to addr
+// through the link and waits for a reply
+func (b *Bulb) attemptAddr(ctx context.Context, client *Client, req *request, raw []byte, link string, addr *net.UDPAddr, timeout time.Duration) (*message, error) {
+	// Only color changes without a response give way to newer ones
+	latest := req.messageType == protocol.TypeLightSetColor && !req.response
+
+	if err := client.limiter.wait(ctx, b.hardwareAddress, latest); err != nil {
 		return nil, err
 	}
 
-	for _, m := range messages {
-		if m.target != b.hardwareAddress {
-			continue
+	if err := client.conn.write(link, raw, addr); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return client.conn.wait(ctx, req)
+}
+
+func containsMessage(messages []*message, m *message) bool {
+	for _, other := range messages {
+		if other._type == m._type && bytes.Equal(other.payout, m.payout) {
+			return true
 		}
-		return m, nil
 	}
+	return false
+}
 
-	return nil, io.EOF
+// getClient returns the client the bulb was discovered by, bulbs created
+// by hand use the default client
+func (b *Bulb) getClient() *Client {
+	if b.client == nil {
+		return defaultClient
+	}
+	return b.client
 }
 
-func (b *Bulb) sendWithAcknowledgement(msg *message, deadLine time.Duration) error {
-	msg.target = b.hardwareAddress
-	msg.ack_required = true
+// lock guards the bulb fields updated in the background, see Client
+func (b *Bulb) lock() {
+	b.getClient().mu.Lock()
+}
+
+func (b *Bulb) unlock() {
+	b.getClient().mu.Unlock()
+}
+
+// remember stores the address the bulb answered from
+func (b *Bulb) remember(m *message) *message {
+	if m.addr != nil {
+		b.lock()
+		b.ipAddress = m.addr
+		b.iface = m.iface
+		b.link = m.link
+		b.unlock()
+	}
+	return m
+}
+
+// udpAddr returns the unicast address of the bulb or nil when it is unknown
+func (b *Bulb) udpAddr() *net.UDPAddr {
+	b.lock()
+	ipAddress, port := b.ipAddress, int(b.port)
+	b.unlock()
+
+	if ipAddress == nil {
+		return nil
+	}
+
+	var ip net.IP
+
+	switch addr := ipAddress.(type) {
+	case *net.UDPAddr:
+		ip = addr.IP
+	case *net.IPAddr:
+		ip = addr.IP
+	default:
+		host, _, err := net.SplitHostPort(addr.String())
+
+		if err != nil {
+			return nil
+		}
+		ip = net.ParseIP(host)
+	}
+
+	if ip == nil {
+		return nil
+	}
+
+	if port == 0 {
+		port = b.getClient().conn.port
+	}
+
+	return &net.UDPAddr{IP: ip, Port: port}
+}
+
+// request sends the payload to the bulb and decodes the reply into response
+func (b *Bulb) request(ctx context.Context, payload protocol.Payload, response protocol.Payload) error {
+	msg, err := makeMessageWithPayload(payload)
 
-	msg, err := b.sendAndReceiveDead(msg, deadLine)
+	if err != nil {
+		return err
+	}
+
+	msg.res_required = true
+
+	reply, err := b.sendAndReceive(ctx, msg, response.Type())
 
 	if err != nil {
 		return err
 	}
 
-	if msg._type != _ACKNOWLEDGEMENT {
-		return ErrNoResponse
+	return response.UnmarshalBinary(reply.payout)
+}
+
+// set sends the payload to the bulb and waits for the acknowledgement
+func (b *Bulb) set(ctx context.Context, payload protocol.Payload) error {
+	msg, err := makeMessageWithPayload(payload)
+
+	if err != nil {
+		return err
 	}
-	return nil
+
+	return b.sendWithAcknowledgement(ctx, msg)
+}
+
+func (b *Bulb) sendWithAcknowledgement(ctx context.Context, msg *message) error {
+	msg.ack_required = true
+
+	_, err := b.sendAndReceive(ctx, msg, protocol.TypeDeviceAcknowledgement)
+	return err
 }
 
 func (b *Bulb) MacAddress() string {
-	mac := make([]byte, 8)
-	writeUInt64(mac, b.hardwareAddress)
-	return strings.Replace(fmt.Sprintf("% x", mac[0:6]), " ", ":", -1)
+	return FormatMAC(b.hardwareAddress)
 }
 
 func (b *Bulb) SetHardwareAddress(address uint64) {
 	b.hardwareAddress = address
 }
 
+// HardwareAddress returns the MAC address in the form used as message target
+func (b *Bulb) HardwareAddress() uint64 {
+	return b.hardwareAddress
+}
+
+// ParseMAC parses a MAC address such as "d0:73:d5:01:02:03" into the form
+// accepted by NewBulb and SetHardwareAddress
+func ParseMAC(s string) (uint64, error) {
+	mac, err := net.ParseMAC(s)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(mac) != 6 {
+		return 0, ErrInvalidMAC
+	}
+
+	buff := make([]byte, 8)
+	copy(buff, mac)
+
+	var address uint64
+	readUint64(buff, &address)
+
+	return address, nil
+}
+
+// FormatMAC formats a hardware address in the form returned by ParseMAC,
+// e.g. "d0:73:d5:01:02:03"
+func FormatMAC(address uint64) string {
+	mac := make([]byte, 8)
+	writeUInt64(mac, address)
+	return net.HardwareAddr(mac[:6]).String()
+}
+
 func (b *Bulb) IP() net.Addr {
+	b.lock()
+	defer b.unlock()
+
 	return b.ipAddress
 }
 
-func (b *Bulb) GetPowerState() (bool, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_POWER))
+// Interface returns the name of the network interface the bulb was found
+// on, it is empty for bulbs found through the default socket
+func (b *Bulb) Interface() string {
+	b.lock()
+	defer b.unlock()
+
+	return b.iface
+}
+
+// linkKey returns the key of the link the bulb answers through
+func (b *Bulb) linkKey() string {
+	b.lock()
+	defer b.unlock()
+
+	return b.link
+}
+
+func (b *Bulb) Verify() error {
+	return b.VerifyContext(context.Background())
+}
+
+// VerifyContext asks the device at the bulb IP for its services and checks
+// it answers with the bulb MAC address. The request is retried like any
+// other, the port is updated from the reply.
+func (b *Bulb) VerifyContext(ctx context.Context) error {
+	if b.udpAddr() == nil {
+		return ErrUnknownAddress
+	}
+
+	msg := makeMessage()
+	msg.tagged = true
+	msg._type = protocol.TypeDeviceGetService
+
+	response, err := b.sendAndReceive(ctx, msg, protocol.TypeDeviceStateService)
 
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	if msg._type != _STATE_POWER {
-		return false, ErrIncorrectResponseType
+	service := &protocol.DeviceStateService{}
+
+	if err := service.UnmarshalBinary(response.payout); err != nil {
+		e := b.newError(msg, protocol.TypeDeviceStateService, 0, err)
+		e.ReceivedType = response._type
+		return e
 	}
 
-	var state uint16
+	b.lock()
+	b.port = service.Port
+	b.unlock()
 
-	readUint16(msg.payout, &state)
+	return nil
+}
 
-	b.powerState = state != 0
-	return b.powerState, nil
+func (b *Bulb) GetPowerState() (bool, error) {
+	return b.GetPowerStateContext(context.Background())
 }
 
-func (b *Bulb) SetPowerState(state bool) error {
-	msg := makeMessageWithType(_SET_POWER)
-	msg.payout = []byte{0, 0}
+func (b *Bulb) GetPowerStateContext(ctx context.Context) (bool, error) {
+	state := &protocol.DeviceStatePower{}
 
-	if state {
-		msg.payout = []byte{0xFF, 0xFF}
+	if err := b.request(ctx, &protocol.DeviceGetPower{}, state); err != nil {
+		return false, err
 	}
 
-	err := b.sendWithAcknowledgement(msg, time.Millisecond*500)
+	power := state.Level != 0
+
+	b.lock()
+	b.powerState = power
+	b.unlock()
+
+	return power, nil
+}
+
+func (b *Bulb) SetPowerState(state bool) error {
+	return b.SetPowerStateContext(context.Background(), state)
+}
+
+func (b *Bulb) SetPowerStateContext(ctx context.Context, state bool) error {
+	err := b.set(ctx, &protocol.DeviceSetPower{Level: powerLevel(state)})
 
 	if err != nil {
 		return err
 	}
 
+	b.lock()
 	b.powerState = state
+	b.unlock()
+
 	return nil
 }
 
 func (b *Bulb) GetLabel() (string, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_LABEL))
+	return b.GetLabelContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetLabelContext(ctx context.Context) (string, error) {
+	state := &protocol.DeviceStateLabel{}
+
+	if err := b.request(ctx, &protocol.DeviceGetLabel{}, state); err != nil {
 		return "", err
 	}
 
-	if msg._type != _STATE_LABEL {
-		return "", ErrIncorrectResponseType
-	}
+	label := trimString(state.Label[:])
 
-	b.label = string(bytes.Trim(msg.payout, "\x00"))
+	b.lock()
+	b.label = label
+	b.unlock()
 
-	return b.label, nil
+	return label, nil
 }
 
 func (b *Bulb) SetLabel(label string) error {
-	msg := makeMessageWithType(_SET_LABEL)
-
-	msg.payout = []byte(label)
+	return b.SetLabelContext(context.Background(), label)
+}
 
-	if len(msg.payout) > 32 {
-		msg.payout = msg.payout[:32]
-	} else {
-		msg.payout = append(msg.payout, make([]byte, 32-len(msg.payout))...)
-	}
+func (b *Bulb) SetLabelContext(ctx context.Context, label string) error {
+	payload := &protocol.DeviceSetLabel{}
+	copy(payload.Label[:], label)
 
-	err := b.sendWithAcknowledgement(msg, time.Millisecond*500)
+	err := b.set(ctx, payload)
 
 	if err != nil {
 		return err
 	}
 
+	b.lock()
 	b.label = label
+	b.unlock()
+
 	return nil
 }
 
 func (b *Bulb) GetStateHostInfo() (*BulbSignalInfo, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_HOST_INFO))
+	return b.GetStateHostInfoContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetStateHostInfoContext(ctx context.Context) (*BulbSignalInfo, error) {
+	state := &protocol.DeviceStateHostInfo{}
+
+	if err := b.request(ctx, &protocol.DeviceGetHostInfo{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_HOST_INFO {
-		return nil, ErrIncorrectResponseType
-	}
+	hostInfo := &BulbSignalInfo{Signal: state.Signal, Tx: state.Tx, Rx: state.Rx}
+
+	b.lock()
+	b.stateHostInfo = hostInfo
+	b.unlock()
 
-	b.stateHostInfo = parseSignal(msg.payout)
-	return b.stateHostInfo, nil
+	return hostInfo, nil
 }
 
 func (b *Bulb) GetWifiInfo() (*BulbSignalInfo, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_WIFI_INFO))
+	return b.GetWifiInfoContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetWifiInfoContext(ctx context.Context) (*BulbSignalInfo, error) {
+	state := &protocol.DeviceStateWifiInfo{}
+
+	if err := b.request(ctx, &protocol.DeviceGetWifiInfo{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_WIFI_INFO {
-		return nil, ErrIncorrectResponseType
-	}
+	wifiInfo := &BulbSignalInfo{Signal: state.Signal, Tx: state.Tx, Rx: state.Rx}
 
-	b.wifiInfo = parseSignal(msg.payout)
-	return b.wifiInfo, nil
-}
+	b.lock()
+	b.wifiInfo = wifiInfo
+	b.unlock()
 
-func parseSignal(payout []byte) *BulbSignalInfo {
-	signalInfo := &BulbSignalInfo{}
-
-	readFloat32(payout[:4], &signalInfo.Signal)
-	readUint32(payout[4:8], &signalInfo.Tx)
-	readUint32(payout[8:12], &signalInfo.Rx)
-	return signalInfo
+	return wifiInfo, nil
 }
 
 func (b *Bulb) GetVersion() (*BulbVersion, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_VERSION))
+	return b.GetVersionContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetVersionContext(ctx context.Context) (*BulbVersion, error) {
+	state := &protocol.DeviceStateVersion{}
+
+	if err := b.request(ctx, &protocol.DeviceGetVersion{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_VERSION {
-		return nil, ErrIncorrectResponseType
+	version := &BulbVersion{
+		VendorId:  state.Vendor,
+		ProductId: state.Product,
+		Version:   state.Version,
 	}
 
-	b.version = &BulbVersion{}
+	b.lock()
+	b.version = version
+	b.unlock()
 
-	readUint32(msg.payout[:4], &b.version.VendorId)
-	readUint32(msg.payout[4:8], &b.version.ProductId)
-	readUint32(msg.payout[8:], &b.version.Version)
-
-	return b.version, nil
+	return version, nil
 }
 
 func (b *Bulb) GetHostFirmware() (*BulbFirmware, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_HOST_FIRMWARE))
+	return b.GetHostFirmwareContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetHostFirmwareContext(ctx context.Context) (*BulbFirmware, error) {
+	state := &protocol.DeviceStateHostFirmware{}
+
+	if err := b.request(ctx, &protocol.DeviceGetHostFirmware{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_HOST_FIRMWARE {
-		return nil, ErrIncorrectResponseType
+	firmware := &BulbFirmware{
+		Build:   state.Build,
+		Version: uint32(state.VersionMajor)<<16 | uint32(state.VersionMinor),
 	}
 
-	b.hostFirmware = parseFirmware(msg.payout)
+	b.lock()
+	b.hostFirmware = firmware
+	b.unlock()
 
-	return b.hostFirmware, nil
+	return firmware, nil
 }
 
 func (b *Bulb) GetWifiFirmware() (*BulbFirmware, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_WIFI_FIRMWARE))
+	return b.GetWifiFirmwareContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetWifiFirmwareContext(ctx context.Context) (*BulbFirmware, error) {
+	state := &protocol.DeviceStateWifiFirmware{}
+
+	if err := b.request(ctx, &protocol.DeviceGetWifiFirmware{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_WIFI_FIRMWARE {
-		return nil, ErrIncorrectResponseType
+	firmware := &BulbFirmware{
+		Build:   state.Build,
+		Version: uint32(state.VersionMajor)<<16 | uint32(state.VersionMinor),
 	}
 
-	b.wifiFirmware = parseFirmware(msg.payout)
+	b.lock()
+	b.wifiFirmware = firmware
+	b.unlock()
 
-	return b.wifiFirmware, nil
+	return firmware, nil
 }
 
-func parseFirmware(payout []byte) *BulbFirmware {
-	firmware := &BulbFirmware{}
-
-	readUint64(payout[:8], &firmware.Build)
-	readUint32(payout[16:], &firmware.Version)
-
-	return firmware
+func (b *Bulb) GetInfo() (*BulbStateInfo, error) {
+	return b.GetInfoContext(context.Background())
 }
 
-func (b *Bulb) GetInfo() (*BulbStateInfo, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_INFO))
+func (b *Bulb) GetInfoContext(ctx context.Context) (*BulbStateInfo, error) {
+	state := &protocol.DeviceStateInfo{}
 
-	if err != nil {
+	if err := b.request(ctx, &protocol.DeviceGetInfo{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_INFO {
-		return nil, ErrIncorrectResponseType
+	info := &BulbStateInfo{
+		Time:     time.Duration(state.Time),
+		UpTime:   time.Duration(state.Uptime),
+		Downtime: time.Duration(state.Downtime),
 	}
 
-	b.info = &BulbStateInfo{}
-
-	var i uint64
+	b.lock()
+	b.info = info
+	b.unlock()
 
-	readUint64(msg.payout[:8], &i)
-	b.info.Time = time.Duration(i)
-	readUint64(msg.payout[8:16], &i)
-	b.info.UpTime = time.Duration(i)
-	readUint64(msg.payout[16:], &i)
-	b.info.Downtime = time.Duration(i)
-
-	return b.info, nil
+	return info, nil
 }
 
 func (b *Bulb) GetLocation() (*BulbLocation, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_LOCATION))
+	return b.GetLocationContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetLocationContext(ctx context.Context) (*BulbLocation, error) {
+	state := &protocol.DeviceStateLocation{}
+
+	if err := b.request(ctx, &protocol.DeviceGetLocation{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_LOCATION {
-		return nil, ErrIncorrectResponseType
+	location := &BulbLocation{
+		Location:  append([]byte(nil), state.Location[:]...),
+		Label:     trimString(state.Label[:]),
+		UpdatedAt: time.Duration(state.UpdatedAt),
 	}
 
-	b.location = parseLocation(msg.payout)
+	b.lock()
+	b.location = location
+	b.unlock()
 
-	return b.location, nil
+	return location, nil
 }
 
 func (b *Bulb) GetGroup() (*BulbLocation, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_GROUP))
+	return b.GetGroupContext(context.Background())
+}
 
-	if err != nil {
+func (b *Bulb) GetGroupContext(ctx context.Context) (*BulbLocation, error) {
+	state := &protocol.DeviceStateGroup{}
+
+	if err := b.request(ctx, &protocol.DeviceGetGroup{}, state); err != nil {
 		return nil, err
 	}
 
-	if msg._type != _STATE_GROUP {
-		return nil, ErrIncorrectResponseType
+	group := &BulbLocation{
+		Location:  append([]byte(nil), state.Group[:]...),
+		Label:     trimString(state.Label[:]),
+		UpdatedAt: time.Duration(state.UpdatedAt),
 	}
 
-	b.group = parseLocation(msg.payout)
+	b.lock()
+	b.group = group
+	b.unlock()
 
-	return b.group, nil
-}
-
-func parseLocation(payout []byte) *BulbLocation {
-	location := &BulbLocation{}
-	location.Location = payout[:16]
-	location.Label = string(bytes.Trim(payout[16:48], "\x00"))
-	var i uint64
-	readUint64(payout[48:], &i)
-	location.UpdatedAt = time.Duration(i)
-
-	return location
+	return group, nil
 }
 
 var (
@@ -378,190 +783,216 @@ var (
 )
 
 func (b *Bulb) EchoRequest(echoRequest []byte) ([]byte, error) {
-	if len(echoRequest) > 64 {
+	return b.EchoRequestContext(context.Background(), echoRequest)
+}
+
+func (b *Bulb) EchoRequestContext(ctx context.Context, echoRequest []byte) ([]byte, error) {
+	payload := &protocol.DeviceEchoRequest{}
+
+	if len(echoRequest) > len(payload.Echoing) {
 		return nil, ErrEchoMaxRequest
 	}
 
-	msg := makeMessageWithType(_ECHO_REQUEST)
-	msg.payout = echoRequest
-	msg, err := b.sendAndReceive(msg)
+	copy(payload.Echoing[:], echoRequest)
 
-	if msg._type != _ECHO_RESPONSE {
-		return nil, ErrIncorrectResponseType
-	}
+	response := &protocol.DeviceEchoResponse{}
 
-	if err != nil {
+	if err := b.request(ctx, payload, response); err != nil {
 		return nil, err
 	}
 
-	return msg.payout, nil
+	return response.Echoing[:], nil
 }
 
 func (b *Bulb) GetPowerDurationState() (bool, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET_POWER_DURATION))
+	return b.GetPowerDurationStateContext(context.Background())
+}
 
-	if err != nil {
-		return false, err
-	}
+func (b *Bulb) GetPowerDurationStateContext(ctx context.Context) (bool, error) {
+	state := &protocol.LightStatePower{}
 
-	if msg._type != _POWER_STATE_DURATION {
-		return false, ErrIncorrectResponseType
+	if err := b.request(ctx, &protocol.LightGetPower{}, state); err != nil {
+		return false, err
 	}
 
-	var state uint16
+	power := state.Level != 0
 
-	readUint16(msg.payout, &state)
+	b.lock()
+	b.powerState = power
+	b.unlock()
 
-	b.powerState = state != 0
-	return b.powerState, nil
+	return power, nil
 }
 
 func (b *Bulb) SetPowerDurationState(state bool, duration uint32) error {
-	msg := makeMessageWithType(_SET_POWER_DURATION)
-
-	msg.payout = make([]byte, 6)
-
-	if state {
-		msg.payout[0], msg.payout[1] = 0xFF, 0xFF
-	}
-
-	if duration > 0 {
-		writeUInt32(msg.payout[2:], duration)
-	}
+	return b.SetPowerDurationStateContext(context.Background(), state, duration)
+}
 
-	err := b.sendWithAcknowledgement(msg, time.Millisecond*500)
+func (b *Bulb) SetPowerDurationStateContext(ctx context.Context, state bool, duration uint32) error {
+	err := b.set(ctx, &protocol.LightSetPower{
+		Level:    powerLevel(state),
+		Duration: duration,
+	})
 
 	if err != nil {
 		return err
 	}
 
+	b.lock()
 	b.powerState = state
+	b.unlock()
+
 	return nil
 }
 
 func (h *HSBK) Write(data []byte) (n int, err error) {
-	readUint16(data[:2], &h.Hue)
-	readUint16(data[2:4], &h.Saturation)
-	readUint16(data[4:6], &h.Brightness)
-	readUint16(data[6:8], &h.Kelvin)
-	return 8, nil
+	color := protocol.LightHsbk{}
+
+	if err := color.UnmarshalBinary(data); err != nil {
+		return 0, err
+	}
+
+	*h = hsbkFromProtocol(color)
+	return color.Size(), nil
 }
 
 func (h *HSBK) Read(p []byte) (n int, err error) {
-	data := make([]byte, 8)
-	writeUInt16(data[:2], h.Hue)
-	writeUInt16(data[2:4], h.Saturation)
-	writeUInt16(data[4:6], h.Brightness)
-	writeUInt16(data[6:8], h.Kelvin)
-
+	data, _ := h.toProtocol().MarshalBinary()
 	copy(p, data)
 
-	return 8, io.EOF
+	return len(data), io.EOF
 }
 
-func (b *Bulb) GetColorState() (*BulbState, error) {
-	msg, err := b.sendAndReceive(makeMessageWithType(_GET))
-
-	if err != nil {
-		return nil, err
+func hsbkFromProtocol(color protocol.LightHsbk) HSBK {
+	return HSBK{
+		Hue:        color.Hue,
+		Saturation: color.Saturation,
+		Brightness: color.Brightness,
+		Kelvin:     color.Kelvin,
 	}
+}
 
-	if msg._type != _STATE {
-		return nil, ErrIncorrectResponseType
+func (h *HSBK) toProtocol() *protocol.LightHsbk {
+	return &protocol.LightHsbk{
+		Hue:        h.Hue,
+		Saturation: h.Saturation,
+		Brightness: h.Brightness,
+		Kelvin:     h.Kelvin,
 	}
-
-	state := parseColorState(msg.payout)
-	b.powerState = state.Power
-	b.label = state.Label
-	b.color = state.Color
-	return state, nil
 }
 
-func (b *Bulb) SetColorState(hsbk *HSBK, duration uint32) error {
-	msg := makeMessageWithType(_SET_COLOR)
-	msg.payout = make([]byte, 13)
+func (b *Bulb) GetColorState() (*BulbState, error) {
+	return b.GetColorStateContext(context.Background())
+}
 
-	hsbk.Read(msg.payout[1:9])
+func (b *Bulb) GetColorStateContext(ctx context.Context) (*BulbState, error) {
+	state := &protocol.LightState{}
 
-	if duration > 0 {
-		writeUInt32(msg.payout[9:], duration)
+	if err := b.request(ctx, &protocol.LightGet{}, state); err != nil {
+		return nil, err
 	}
 
-	err := b.sendWithAcknowledgement(msg, time.Millisecond*500)
+	return b.updateColorState(state), nil
+}
+
+func (b *Bulb) SetColorState(hsbk *HSBK, duration uint32) error {
+	return b.SetColorStateContext(context.Background(), hsbk, duration)
+}
+
+func (b *Bulb) SetColorStateContext(ctx context.Context, hsbk *HSBK, duration uint32) error {
+	err := b.set(ctx, &protocol.LightSetColor{
+		Color:    *hsbk.toProtocol(),
+		Duration: duration,
+	})
 
 	if err != nil {
 		return err
 	}
 
-	b.color = hsbk
+	// The caller may go on changing hsbk
+	color := *hsbk
+
+	b.lock()
+	b.color = &color
+	b.unlock()
 
 	return nil
 }
 
 func (b *Bulb) SetColorStateWithResponse(hsbk *HSBK, duration uint32) (*BulbState, error) {
-	msg := makeMessageWithType(_SET_COLOR)
-	msg.res_required = true
-	msg.payout = make([]byte, 13)
-
-	hsbk.Read(msg.payout[1:9])
+	return b.SetColorStateWithResponseContext(context.Background(), hsbk, duration)
+}
 
-	if duration > 0 {
-		writeUInt32(msg.payout[9:], duration)
+func (b *Bulb) SetColorStateWithResponseContext(ctx context.Context, hsbk *HSBK, duration uint32) (*BulbState, error) {
+	payload := &protocol.LightSetColor{
+		Color:    *hsbk.toProtocol(),
+		Duration: duration,
 	}
 
-	msg, err := b.sendAndReceive(msg)
+	state := &protocol.LightState{}
 
-	if err != nil {
+	if err := b.request(ctx, payload, state); err != nil {
 		return nil, err
 	}
 
-	state := parseColorState(msg.payout)
-	b.powerState = state.Power
-	b.label = state.Label
-	b.color = state.Color
-	return state, nil
+	return b.updateColorState(state), nil
 }
 
 func (b *Bulb) SetWaveform(transient bool, hsbk *HSBK, period uint32, cycles float32, skewRatio int16, waveform uint8) (*BulbState, error) {
-	msg := makeMessageWithType(_SET_WAVEFORM)
-	msg.res_required = true
-	msg.payout = make([]byte, 21)
+	return b.SetWaveformContext(context.Background(), transient, hsbk, period, cycles, skewRatio, waveform)
+}
 
-	msg.payout[1] = boolToUInt8(transient)
-	hsbk.Read(msg.payout[2:10])
-	writeUInt32(msg.payout[10:], period)
-	writeFloat32(msg.payout[14:], cycles)
-	writeInt16(msg.payout[18:], skewRatio)
-	msg.payout[20] = waveform
+func (b *Bulb) SetWaveformContext(ctx context.Context, transient bool, hsbk *HSBK, period uint32, cycles float32, skewRatio int16, waveform uint8) (*BulbState, error) {
+	payload := &protocol.LightSetWaveform{
+		Transient: transient,
+		Color:     *hsbk.toProtocol(),
+		Period:    period,
+		Cycles:    cycles,
+		SkewRatio: skewRatio,
+		Waveform:  protocol.LightWaveform(waveform),
+	}
 
-	msg, err := b.sendAndReceive(msg)
+	state := &protocol.LightState{}
 
-	if err != nil {
+	if err := b.request(ctx, payload, state); err != nil {
 		return nil, err
 	}
 
-	state := parseColorState(msg.payout)
-	b.powerState = state.Power
-	b.label = state.Label
-	b.color = state.Color
-	return state, nil
+	return b.updateColorState(state), nil
 }
 
-func parseColorState(payout []byte) *BulbState {
-	hsbk := &HSBK{}
-	hsbk.Write(payout[:8])
-
-	state := &BulbState{Color: hsbk}
+// updateColorState caches a LightState and returns it as BulbState
+func (b *Bulb) updateColorState(light *protocol.LightState) *BulbState {
+	hsbk := hsbkFromProtocol(light.Color)
+	// The caller gets its own copy of the cached color
+	cached := hsbk
 
-	var powerState uint16
-	readUint16(payout[10:12], &powerState)
+	state := &BulbState{
+		Color: &hsbk,
+		Power: light.Power != 0,
+		Label: trimString(light.Label[:]),
+	}
 
-	state.Power = powerState != 0
+	b.lock()
+	b.powerState = state.Power
+	b.label = state.Label
+	b.color = &cached
+	b.unlock()
 
-	state.Label = string(bytes.Trim(payout[12:44], "\x00"))
 	return state
 }
 
+func powerLevel(state bool) uint16 {
+	if state {
+		return 0xFFFF
+	}
+	return 0
+}
+
+func trimString(data []byte) string {
+	return string(bytes.Trim(data, "\x00"))
+}
+
 func (b BulbSignalInfo) String() string {
 	return fmt.Sprintf("Signal: %f\nRx: %d\nTx: %d\n", b.Signal, b.Rx, b.Tx)
 }
@@ -590,49 +1021,59 @@ func (b BulbState) String() string {
 	return fmt.Sprintf("Color: %sPower: %t\nLabel: %s\n", b.Color, b.Power, b.Label)
 }
 
-func (b Bulb) String() string {
-	str := fmt.Sprintf("MAC: %s\nIP: %s\n", b.MacAddress(), b.IP())
+// snapshot returns a copy of the bulb taken under the lock, its cached
+// fields are updated in the background
+func (b *Bulb) snapshot() Bulb {
+	b.lock()
+	defer b.unlock()
+
+	return *b
+}
+
+func (b *Bulb) String() string {
+	s := b.snapshot()
+	str := fmt.Sprintf("MAC: %s\nIP: %s\n", s.MacAddress(), s.ipAddress)
 
-	if b.label != "" {
-		str += fmt.Sprintf("Label: %s\n", b.label)
+	if s.label != "" {
+		str += fmt.Sprintf("Label: %s\n", s.label)
 	}
 
-	str += fmt.Sprintf("Power state: %t\n", b.powerState)
+	str += fmt.Sprintf("Power state: %t\n", s.powerState)
 
-	if b.stateHostInfo != nil {
-		str += fmt.Sprintf("Host info:\n%s", b.stateHostInfo)
+	if s.stateHostInfo != nil {
+		str += fmt.Sprintf("Host info:\n%s", s.stateHostInfo)
 	}
 
-	if b.wifiInfo != nil {
-		str += fmt.Sprintf("Wi-Fi info:\n%s", b.wifiInfo)
+	if s.wifiInfo != nil {
+		str += fmt.Sprintf("Wi-Fi info:\n%s", s.wifiInfo)
 	}
 
-	if b.version != nil {
-		str += fmt.Sprintf("Version:\n%s", b.version)
+	if s.version != nil {
+		str += fmt.Sprintf("Version:\n%s", s.version)
 	}
 
-	if b.hostFirmware != nil {
-		str += fmt.Sprintf("Host Firmware:\n%s", b.hostFirmware)
+	if s.hostFirmware != nil {
+		str += fmt.Sprintf("Host Firmware:\n%s", s.hostFirmware)
 	}
 
-	if b.wifiFirmware != nil {
-		str += fmt.Sprintf("Wi-Fi Firmware:\n%s", b.wifiFirmware)
+	if s.wifiFirmware != nil {
+		str += fmt.Sprintf("Wi-Fi Firmware:\n%s", s.wifiFirmware)
 	}
 
-	if b.info != nil {
-		str += fmt.Sprintf("Info:\n%s", b.info)
+	if s.info != nil {
+		str += fmt.Sprintf("Info:\n%s", s.info)
 	}
 
-	if b.location != nil {
-		str += fmt.Sprintf("Location:\n%s", b.location)
+	if s.location != nil {
+		str += fmt.Sprintf("Location:\n%s", s.location)
 	}
 
-	if b.group != nil {
-		str += fmt.Sprintf("Group:\n%s", b.group)
+	if s.group != nil {
+		str += fmt.Sprintf("Group:\n%s", s.group)
 	}
 
-	if b.color != nil {
-		str += fmt.Sprintf("Color:\n%s", b.color)
+	if s.color != nil {
+		str += fmt.Sprintf("Color:\n%s", s.color)
 	}
 
 	return str
@@ -0,0 +1,21 @@
+package golifx
+
+import (
+	"testing"
+)
+
+func TestMAC(t *testing.T) {
+	address, err := ParseMAC("d0:73:d5:00:00:01")
+
+	if err != nil || address != _TEST_MAC {
+		t.Fatalf("got %x, %v, want %x", address, err, uint64(_TEST_MAC))
+	}
+
+	if got := FormatMAC(address); got != "d0:73:d5:00:00:01" {
+		t.Fatalf("got %s", got)
+	}
+
+	if _, err = ParseMAC("d0:73:d5:00:00:01:02:03"); err != ErrInvalidMAC {
+		t.Fatalf("got %v, want ErrInvalidMAC", err)
+	}
+}
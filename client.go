@@ -0,0 +1,261 @@
+package golifx
+
+import (
+	"context"
+	"crypto/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+type (
+	// Client owns the network settings used to discover and talk to bulbs.
+	// Bulbs found by a client keep a reference to it and use its settings
+	// for every request. The client keeps one Bulb per MAC address, so
+	// discovery and Subscribe update the bulbs already handed out.
+	Client struct {
+		conn        *connection
+		source      uint32
+		deadline    time.Duration
+		retryPolicy RetryPolicy
+		limiter     *rateLimiter
+
+		// mu guards the bulb registry and the bulb fields updated in the
+		// background: address, power, color and label
+		mu    sync.Mutex
+		bulbs map[uint64]*Bulb
+	}
+
+	// ClientOption configures a Client created with NewClient
+	ClientOption func(*Client)
+)
+
+// NewClient returns a client with default settings modified by options
+func NewClient(options ...ClientOption) *Client {
+	c := &Client{
+		conn: &connection{
+			bcastAddress: net.IPv4bcast,
+			port:         _DEFAULT_PORT,
+			interfaces:   true,
+			listenPort:   true,
+		},
+		source:      randomSource(),
+		deadline:    _DEFAULT_MAX_DEAD_LINE,
+		retryPolicy: DefaultRetryPolicy,
+		limiter:     newRateLimiter(DefaultRateLimit, DefaultBroadcastRateLimit),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// WithBroadcastAddress sets the address discovery messages are sent to,
+// it turns interface discovery off
+func WithBroadcastAddress(addr net.IP) ClientOption {
+	return func(c *Client) {
+		c.conn.bcastAddress = addr
+		c.conn.interfaces = false
+	}
+}
+
+// WithInterfaceDiscovery turns sending discovery messages to the broadcast
+// address of every local IPv4 subnet on or off. It is on by default.
+func WithInterfaceDiscovery(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.conn.interfaces = enabled
+	}
+}
+
+// WithPortListener turns the socket Subscribe binds to the bulb port on or
+// off. It is on by default and Subscribe fails when another program holds
+// the port. Without it only the state packets sent to the client sockets
+// arrive, e.g. the ones of lifxtest.Server.NotifyClients.
+func WithPortListener(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.conn.listenPort = enabled
+	}
+}
+
+// WithPort sets the UDP port bulbs listen on
+func WithPort(port int) ClientOption {
+	return func(c *Client) {
+		c.conn.port = port
+	}
+}
+
+// WithSource sets the source identifier put in every message header.
+// Bulbs broadcast replies to messages with source 0 so zero keeps the
+// random source picked by NewClient.
+func WithSource(source uint32) ClientOption {
+	return func(c *Client) {
+		if source != 0 {
+			c.source = source
+		}
+	}
+}
+
+// WithDeadline sets how long the client waits for responses, requests
+// including their retries fail within it unless the retry policy sets a
+// Timeout
+func WithDeadline(deadline time.Duration) ClientOption {
+	return func(c *Client) {
+		c.deadline = deadline
+	}
+}
+
+// WithRetryPolicy sets the policy used for requests expecting a reply,
+// ContextWithRetryPolicy overrides it for a single call
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRateLimit sets the token bucket every bulb gets, messages over the
+// limit wait for their turn. A zero Rate turns the limit off.
+func WithRateLimit(limit RateLimit) ClientOption {
+	return func(c *Client) {
+		c.limiter.bulb = limit
+	}
+}
+
+// WithBroadcastRateLimit sets the token bucket shared by all broadcasts
+func WithBroadcastRateLimit(limit RateLimit) ClientOption {
+	return func(c *Client) {
+		c.limiter.broadcast = newTokenBucket(limit)
+	}
+}
+
+// WithLocalAddress binds the client socket to a local address, it turns
+// interface discovery off
+func WithLocalAddress(addr *net.UDPAddr) ClientOption {
+	return func(c *Client) {
+		c.conn.localAddr = addr
+		c.conn.interfaces = false
+	}
+}
+
+// LookupBulbs broadcasts GetService and returns every bulb that answered
+// before the client deadline
+func (c *Client) LookupBulbs() ([]*Bulb, error) {
+	return c.LookupBulbsContext(context.Background())
+}
+
+// LookupBulbsContext is like LookupBulbs but listens for answers until ctx
+// is done. The client deadline is used when ctx has no deadline.
+func (c *Client) LookupBulbsContext(ctx context.Context) ([]*Bulb, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.deadline)
+		defer cancel()
+	}
+
+	events, err := c.Discover(ctx, DiscoverOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	bulbs := []*Bulb{}
+
+	for event := range events {
+		bulbs = append(bulbs, event.Bulb)
+	}
+
+	if ctx.Err() == context.Canceled {
+		return bulbs, ctx.Err()
+	}
+
+	return bulbs, nil
+}
+
+// NewBulb returns a bulb bound to the client for a device with known IP and
+// MAC address, see ParseMAC. Requests are sent unicast to the default port.
+func (c *Client) NewBulb(ip net.IP, hardwareAddress uint64) *Bulb {
+	return c.addBulb(&Bulb{
+		client:          c,
+		hardwareAddress: hardwareAddress,
+		ipAddress:       &net.UDPAddr{IP: ip, Port: c.conn.port},
+		port:            uint32(c.conn.port),
+	})
+}
+
+// Bulbs returns every bulb the client knows about, found by discovery,
+// created with NewBulb or heard from by Subscribe
+func (c *Client) Bulbs() []*Bulb {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bulbs := make([]*Bulb, 0, len(c.bulbs))
+
+	for _, bulb := range c.bulbs {
+		bulbs = append(bulbs, bulb)
+	}
+
+	return bulbs
+}
+
+// addBulb returns the known bulb with the MAC address of bulb, updating its
+// address, or registers bulb if the MAC address is new
+func (c *Client) addBulb(bulb *Bulb) *Bulb {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known, ok := c.bulbs[bulb.hardwareAddress]
+
+	if !ok {
+		if c.bulbs == nil {
+			c.bulbs = map[uint64]*Bulb{}
+		}
+
+		c.bulbs[bulb.hardwareAddress] = bulb
+		return bulb
+	}
+
+	if bulb.ipAddress != nil {
+		known.ipAddress = bulb.ipAddress
+		known.iface = bulb.iface
+		known.link = bulb.link
+	}
+
+	if bulb.port != 0 {
+		known.port = bulb.port
+	}
+
+	return known
+}
+
+// Close closes the client sockets, requests in flight and any later use of
+// the client fail with ErrConnectionClosed
+func (c *Client) Close() error {
+	return c.conn.close()
+}
+
+func (c *Client) makeMessage() *message {
+	msg := makeMessage()
+	msg.source = c.source
+	return msg
+}
+
+// randomSource picks a source identifier so replies to different clients,
+// even in different processes, can be told apart
+func randomSource() uint32 {
+	buff := make([]byte, 4)
+
+	for {
+		if _, err := rand.Read(buff); err != nil {
+			return _DEFAULT_SOURCE_VALUE
+		}
+
+		var source uint32
+		readUint32(buff, &source)
+
+		// 0 makes bulbs broadcast their replies
+		if source != 0 {
+			return source
+		}
+	}
+}
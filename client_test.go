@@ -0,0 +1,117 @@
+package golifx
+
+import (
+	"testing"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+func TestSource(t *testing.T) {
+	first, second := NewClient(), NewClient()
+	defer first.Close()
+	defer second.Close()
+
+	if first.source == 0 || first.source == second.source {
+		t.Fatalf("sources %d and %d, want two random ones", first.source, second.source)
+	}
+
+	if client := NewClient(WithSource(0)); client.source == 0 {
+		t.Fatal("WithSource(0) replaced the random source")
+	}
+
+	if client := NewClient(WithSource(7)); client.makeMessage().source != 7 {
+		t.Fatal("WithSource(7) is not used by messages")
+	}
+}
+
+func TestRegisterSequence(t *testing.T) {
+	transport, err := NewMemoryNetwork().Listen(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(WithTransport(transport))
+	defer client.Close()
+
+	conn := client.conn
+	held := client.makeMessage()
+
+	if _, err = conn.register(held); err != nil {
+		t.Fatal(err)
+	}
+
+	// Numbers wrap at 255 and skip the one still in flight
+	last := held.sequence
+
+	for i := 0; i < 300; i++ {
+		msg := client.makeMessage()
+		req, err := conn.register(msg)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := last + 1
+
+		if want == held.sequence {
+			want++
+		}
+
+		if msg.sequence != want {
+			t.Fatalf("got sequence %d after %d with %d in flight, want %d", msg.sequence, last, held.sequence, want)
+		}
+
+		last = msg.sequence
+		conn.unregister(msg, req)
+	}
+
+	for i := 0; i < 255; i++ {
+		if _, err = conn.register(client.makeMessage()); err != nil {
+			t.Fatalf("request %d: %s", i, err)
+		}
+	}
+
+	if _, err = conn.register(client.makeMessage()); err != ErrTooManyRequests {
+		t.Fatalf("got %v with every sequence number in flight, want ErrTooManyRequests", err)
+	}
+}
+
+func TestRequestAccepts(t *testing.T) {
+	const source = 42
+
+	reply := func(messageType uint16, source uint32, target uint64) *message {
+		msg := makeMessage()
+		msg._type = messageType
+		msg.source = source
+		msg.target = target
+		return msg
+	}
+
+	get := &request{source: source, target: _TEST_MAC, messageType: protocol.TypeDeviceGetPower}
+	set := &request{source: source, target: _TEST_MAC, messageType: protocol.TypeDeviceSetPower, ack: true}
+	broadcast := &request{source: source, messageType: protocol.TypeDeviceGetService}
+
+	tests := []struct {
+		name string
+		req  *request
+		msg  *message
+		want bool
+	}{
+		{"state", get, reply(protocol.TypeDeviceStatePower, source, _TEST_MAC), true},
+		{"other source", get, reply(protocol.TypeDeviceStatePower, source+1, _TEST_MAC), false},
+		{"other bulb", get, reply(protocol.TypeDeviceStatePower, source, _TEST_MAC+1), false},
+		{"own message", get, reply(protocol.TypeDeviceGetPower, source, _TEST_MAC), false},
+		{"unhandled", get, reply(protocol.TypeDeviceStateUnhandled, source, _TEST_MAC), true},
+		{"ack to a get", get, reply(protocol.TypeDeviceAcknowledgement, source, _TEST_MAC), false},
+		{"ack", set, reply(protocol.TypeDeviceAcknowledgement, source, _TEST_MAC), true},
+		{"state to an acked set", set, reply(protocol.TypeDeviceStatePower, source, _TEST_MAC), false},
+		{"any bulb", broadcast, reply(protocol.TypeDeviceStateService, source, _TEST_MAC+1), true},
+	}
+
+	for _, test := range tests {
+		if got := test.req.accepts(test.msg); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import "syscall"
+
+// bindToDevice restricts a socket to the packets of the interface, kernels
+// before 5.7 require CAP_NET_RAW for it
+func bindToDevice(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var err error
+
+		controlErr := c.Control(func(fd uintptr) {
+			err = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, name)
+		})
+
+		if controlErr != nil {
+			return controlErr
+		}
+		return err
+	}
+}
@@ -0,0 +1,300 @@
+// Command lifx-emulator runs virtual LIFX devices that the LIFX app and
+// golifx clients can discover and control.
+//
+//	lifx-emulator -n 3 -product color,multizone -labels Kitchen,Hall,Desk
+//
+// The state of the devices is saved to a JSON file and restored on the next
+// start, flags given on the command line are applied to the restored
+// devices. A live view shows every device as commands arrive.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/2tvenom/golifx"
+	"github.com/2tvenom/golifx/lifxtest"
+)
+
+var (
+	count     = flag.Int("n", 1, "number of virtual devices, the saved ones by default")
+	iface     = flag.String("interface", "", "network interface to serve, all if empty (Linux only)")
+	port      = flag.Int("port", 56700, "UDP port")
+	product   = flag.String("product", "color", "comma separated products of the devices: color, white, multizone, tile, switch")
+	labels    = flag.String("labels", "", "comma separated labels of the devices")
+	groups    = flag.String("groups", "", "comma separated groups of the devices")
+	locations = flag.String("locations", "", "comma separated locations of the devices")
+	stateFile = flag.String("state", "lifx-emulator.json", "file the devices are restored from and saved to, empty to disable")
+	live      = flag.Bool("view", true, "show a live view of the devices, otherwise log every message")
+)
+
+func main() {
+	flag.Parse()
+
+	devices, err := deviceOptions()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := listen(*iface, *port)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := lifxtest.Serve(conn)
+
+	defer server.Close()
+
+	for _, options := range devices {
+		server.AddDevice(options)
+	}
+
+	e := &emulator{
+		server:  server,
+		last:    map[uint64]uint16{},
+		changed: make(chan struct{}, 1),
+	}
+
+	server.OnMessage(e.message)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	e.run(signals)
+}
+
+// deviceOptions returns the devices saved in the state file with the flags
+// given on the command line applied: -n adds or removes devices, -product,
+// -labels, -groups and -locations replace the saved values. Without a state
+// file the devices are described by the flags alone.
+func deviceOptions() ([]lifxtest.DeviceOptions, error) {
+	var saved []lifxtest.DeviceOptions
+
+	if *stateFile != "" {
+		var err error
+
+		if saved, err = loadState(*stateFile); err != nil {
+			return nil, err
+		}
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	n := *count
+
+	if saved != nil && !set["n"] {
+		n = len(saved)
+	}
+
+	productNames := split(*product)
+
+	if len(productNames) == 0 {
+		productNames = []string{"color"}
+	}
+
+	devices := []lifxtest.DeviceOptions{}
+
+	for i := 0; i < n; i++ {
+		name := productNames[i%len(productNames)]
+		p, ok := products[name]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown product %q", name)
+		}
+
+		if i >= len(saved) {
+			devices = append(devices, lifxtest.DeviceOptions{
+				Product:  p,
+				Label:    pick(split(*labels), i, fmt.Sprintf("Virtual %d", i+1)),
+				Group:    pick(split(*groups), i, "Virtual"),
+				Location: pick(split(*locations), i, "Emulator"),
+				Color:    hsbk{Saturation: 65535, Brightness: 65535, Kelvin: 3500}.toProtocol(),
+			})
+			continue
+		}
+
+		options := saved[i]
+
+		// Zones and relays of another product do not carry over
+		if set["product"] && options.Product != p {
+			options.Product = p
+			options.Zones = nil
+			options.Relays = nil
+		}
+
+		if set["labels"] {
+			options.Label = pick(split(*labels), i, options.Label)
+		}
+
+		if set["groups"] {
+			options.Group = pick(split(*groups), i, options.Group)
+		}
+
+		if set["locations"] {
+			options.Location = pick(split(*locations), i, options.Location)
+		}
+
+		devices = append(devices, options)
+	}
+
+	return devices, nil
+}
+
+// listen opens the socket of the devices on the wildcard address, restricted
+// to the interface when one is given. A socket bound to the address of the
+// interface would not receive the broadcasts of the LIFX app.
+func listen(name string, port int) (*net.UDPConn, error) {
+	config := &net.ListenConfig{}
+
+	if name != "" {
+		if _, err := net.InterfaceByName(name); err != nil {
+			return nil, err
+		}
+
+		config.Control = bindToDevice(name)
+	}
+
+	conn, err := config.ListenPacket(context.Background(), "udp4", fmt.Sprintf(":%d", port))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.(*net.UDPConn), nil
+}
+
+type emulator struct {
+	server *lifxtest.Server
+	// saved is the content of the state file as last written
+	saved []byte
+
+	mu      sync.Mutex
+	last    map[uint64]uint16
+	changed chan struct{}
+}
+
+// message is called by the server for every message a device handled
+func (e *emulator) message(d *lifxtest.Device, messageType uint16) {
+	e.mu.Lock()
+	e.last[d.MAC()] = messageType
+	e.mu.Unlock()
+
+	if !*live {
+		log.Printf("%s %s: %s", golifx.FormatMAC(d.MAC()), d.Label(), strings.TrimSpace(formatColor(d.Color())))
+	}
+
+	select {
+	case e.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run redraws the view after messages and saves the state when it changed,
+// at most ten times a second, until a signal arrives
+func (e *emulator) run(signals chan os.Signal) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	dirty := true
+
+	for {
+		select {
+		case <-e.changed:
+			dirty = true
+		case <-ticker.C:
+			if dirty {
+				e.update()
+				e.save()
+				dirty = false
+			}
+		case <-signals:
+			e.save()
+			return
+		}
+	}
+}
+
+func (e *emulator) update() {
+	if *live {
+		e.mu.Lock()
+		last := map[uint64]uint16{}
+
+		for mac, messageType := range e.last {
+			last[mac] = messageType
+		}
+		e.mu.Unlock()
+
+		buff := &bytes.Buffer{}
+
+		// Clear the terminal and move to the top left corner
+		fmt.Fprint(buff, "\033[H\033[2J")
+		fmt.Fprintf(buff, "LIFX emulator on %s\n\n", e.server.Addr())
+		render(buff, e.server.Devices(), last)
+
+		os.Stdout.Write(buff.Bytes())
+	}
+}
+
+func (e *emulator) save() {
+	if *stateFile == "" {
+		return
+	}
+
+	data, err := encodeState(e.server.Devices())
+
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	// Most messages only read the state, the file is left alone for them
+	if bytes.Equal(data, e.saved) {
+		return
+	}
+
+	if err := writeState(*stateFile, data); err != nil {
+		log.Print(err)
+		return
+	}
+
+	e.saved = data
+}
+
+// split returns the comma separated values of a flag, empty values keep
+// their position so that "-labels ,,Porch" only names the third device
+func split(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	values := strings.Split(s, ",")
+
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	return values
+}
+
+// pick returns the value for the device i or def when it has none
+func pick(values []string, i int, def string) string {
+	if i < len(values) && values[i] != "" {
+		return values[i]
+	}
+	return def
+}
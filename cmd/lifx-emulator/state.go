@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/2tvenom/golifx"
+	"github.com/2tvenom/golifx/lifxtest"
+	"github.com/2tvenom/golifx/protocol"
+)
+
+type (
+	// deviceState is a device as saved in the state file
+	deviceState struct {
+		MAC      string   `json:"mac"`
+		Product  string   `json:"product"`
+		Label    string   `json:"label"`
+		Group    string   `json:"group"`
+		Location string   `json:"location"`
+		Power    bool     `json:"power"`
+		Color    hsbk     `json:"color"`
+		Zones    []hsbk   `json:"zones,omitempty"`
+		Tiles    [][]hsbk `json:"tiles,omitempty"`
+		Relays   []uint16 `json:"relays,omitempty"`
+	}
+
+	hsbk struct {
+		Hue        uint16 `json:"hue"`
+		Saturation uint16 `json:"saturation"`
+		Brightness uint16 `json:"brightness"`
+		Kelvin     uint16 `json:"kelvin"`
+	}
+)
+
+var products = map[string]lifxtest.Product{
+	"color":     lifxtest.ProductColor,
+	"white":     lifxtest.ProductWhite,
+	"multizone": lifxtest.ProductMultizone,
+	"tile":      lifxtest.ProductTile,
+	"switch":    lifxtest.ProductSwitch,
+}
+
+// loadState reads the devices saved in the file, nil is returned when the
+// file does not exist
+func loadState(file string) ([]lifxtest.DeviceOptions, error) {
+	data, err := os.ReadFile(file)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	states := []deviceState{}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	devices := []lifxtest.DeviceOptions{}
+
+	for _, state := range states {
+		mac, err := golifx.ParseMAC(state.MAC)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+
+		product, ok := products[state.Product]
+
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown product %q", file, state.Product)
+		}
+
+		options := lifxtest.DeviceOptions{
+			MAC:      mac,
+			Product:  product,
+			Label:    state.Label,
+			Group:    state.Group,
+			Location: state.Location,
+			Power:    state.Power,
+			Color:    state.Color.toProtocol(),
+			Relays:   state.Relays,
+		}
+
+		for _, zone := range state.Zones {
+			options.Zones = append(options.Zones, zone.toProtocol())
+		}
+
+		for _, tile := range state.Tiles {
+			if len(tile) != 64 {
+				return nil, fmt.Errorf("%s: tiles have 64 colors, not %d", file, len(tile))
+			}
+
+			colors := [64]protocol.LightHsbk{}
+
+			for i, color := range tile {
+				colors[i] = color.toProtocol()
+			}
+
+			options.Tiles = append(options.Tiles, colors)
+		}
+
+		devices = append(devices, options)
+	}
+
+	return devices, nil
+}
+
+// encodeState returns the devices as saved in the state file
+func encodeState(devices []*lifxtest.Device) ([]byte, error) {
+	states := []deviceState{}
+
+	for _, d := range devices {
+		state := deviceState{
+			MAC:      golifx.FormatMAC(d.MAC()),
+			Product:  productName(d.Product()),
+			Label:    d.Label(),
+			Group:    d.Group(),
+			Location: d.Location(),
+			Power:    d.Power(),
+			Color:    hsbkFromProtocol(d.Color()),
+			Relays:   d.Relays(),
+		}
+
+		for _, zone := range d.Zones() {
+			state.Zones = append(state.Zones, hsbkFromProtocol(zone))
+		}
+
+		for _, colors := range d.Tiles() {
+			tile := []hsbk{}
+
+			for _, color := range colors {
+				tile = append(tile, hsbkFromProtocol(color))
+			}
+
+			state.Tiles = append(state.Tiles, tile)
+		}
+
+		states = append(states, state)
+	}
+
+	return json.MarshalIndent(states, "", "  ")
+}
+
+// writeState writes the encoded devices to the file, replacing it atomically
+func writeState(file string, data []byte) error {
+	if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(file+".tmp", file)
+}
+
+func productName(product lifxtest.Product) string {
+	for name, p := range products {
+		if p == product {
+			return name
+		}
+	}
+	return "color"
+}
+
+func hsbkFromProtocol(color protocol.LightHsbk) hsbk {
+	return hsbk{
+		Hue:        color.Hue,
+		Saturation: color.Saturation,
+		Brightness: color.Brightness,
+		Kelvin:     color.Kelvin,
+	}
+}
+
+func (h hsbk) toProtocol() protocol.LightHsbk {
+	return protocol.LightHsbk{
+		Hue:        h.Hue,
+		Saturation: h.Saturation,
+		Brightness: h.Brightness,
+		Kelvin:     h.Kelvin,
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/2tvenom/golifx/lifxtest"
+	"github.com/2tvenom/golifx/protocol"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	server, err := lifxtest.NewServer()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer server.Close()
+
+	tiles := make([][64]protocol.LightHsbk, lifxtest.ProductTile.Tiles)
+	tiles[1][7] = protocol.LightHsbk{Hue: 100, Saturation: 200, Brightness: 300, Kelvin: 3500}
+
+	server.AddDevice(lifxtest.DeviceOptions{Label: "Desk", Product: lifxtest.ProductTile, Power: true, Tiles: tiles})
+	server.AddDevice(lifxtest.DeviceOptions{Label: "Porch", Product: lifxtest.ProductSwitch, Relays: []uint16{65535}})
+
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	data, err := encodeState(server.Devices())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = writeState(file, data); err != nil {
+		t.Fatal(err)
+	}
+
+	devices, err := loadState(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(devices) != 2 {
+		t.Fatalf("got %d devices, want 2", len(devices))
+	}
+
+	desk, porch := devices[0], devices[1]
+
+	if desk.Label != "Desk" || desk.Product != lifxtest.ProductTile || !desk.Power || desk.MAC != server.Devices()[0].MAC() {
+		t.Fatalf("got %+v", desk)
+	}
+
+	if len(desk.Tiles) != len(tiles) || desk.Tiles[1] != tiles[1] {
+		t.Fatalf("tiles were not restored: %+v", desk.Tiles)
+	}
+
+	if porch.Label != "Porch" || len(porch.Relays) != 4 || porch.Relays[0] != 65535 {
+		t.Fatalf("got %+v", porch)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/2tvenom/golifx"
+	"github.com/2tvenom/golifx/lifxtest"
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// render writes a table of the devices, last holds the type of the last
+// message every device handled
+func render(w io.Writer, devices []*lifxtest.Device, last map[uint64]uint16) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "MAC\tLABEL\tPRODUCT\tPOWER\tCOLOR\tZONES/RELAYS\tLAST MESSAGE")
+
+	for _, d := range devices {
+		lastMessage := "-"
+
+		if messageType, ok := last[d.MAC()]; ok {
+			lastMessage = protocol.TypeName(messageType)
+		}
+
+		color := "-"
+
+		if d.Product().HasLight() {
+			color = formatColor(d.Color())
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			golifx.FormatMAC(d.MAC()), d.Label(), d.Product().Name, onOff(d.Power()),
+			color, formatParts(d), lastMessage)
+	}
+
+	tw.Flush()
+}
+
+// formatColor shows a color as hue in degrees, saturation and brightness in
+// percent and kelvin
+func formatColor(color protocol.LightHsbk) string {
+	return fmt.Sprintf("%3.0f° %3.0f%% %3.0f%% %5dK",
+		float64(color.Hue)*360/65536,
+		float64(color.Saturation)*100/65535,
+		float64(color.Brightness)*100/65535,
+		color.Kelvin)
+}
+
+// formatParts shows the brightness of every zone of a strip as a bar and
+// the relays of a switch as 0 and 1
+func formatParts(d *lifxtest.Device) string {
+	const levels = " ▁▂▃▄▅▆▇█"
+
+	parts := []string{}
+
+	for _, zone := range d.Zones() {
+		runes := []rune(levels)
+		parts = append(parts, string(runes[int(zone.Brightness)*(len(runes)-1)/65535]))
+	}
+
+	for _, relay := range d.Relays() {
+		if relay != 0 {
+			parts = append(parts, "1")
+		} else {
+			parts = append(parts, "0")
+		}
+	}
+
+	if len(parts) == 0 {
+		return "-"
+	}
+
+	return strings.Join(parts, "")
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
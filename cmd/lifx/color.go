@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/2tvenom/golifx"
+)
+
+// namedColors are hues in degrees
+var namedColors = map[string]float64{
+	"red":    0,
+	"orange": 36,
+	"yellow": 60,
+	"green":  120,
+	"cyan":   180,
+	"blue":   250,
+	"purple": 280,
+	"pink":   325,
+}
+
+// parseColor applies a color string to the current color of a bulb. The
+// string is a list of space separated components:
+//
+//	red, blue, ...           named hue at full saturation
+//	white                    no saturation
+//	#ff8000                  RGB color
+//	hue:120                  hue in degrees
+//	saturation:0.5           saturation from 0 to 1
+//	brightness:0.5           brightness from 0 to 1
+//	kelvin:2700              white temperature
+//
+// Components not given keep their current value. A kelvin turns the color
+// white unless a hue or saturation is given as well, in any order.
+func parseColor(s string, current golifx.HSBK) (golifx.HSBK, error) {
+	color := current
+	fields := strings.Fields(strings.ToLower(s))
+
+	if len(fields) == 0 {
+		return color, errors.New("empty color")
+	}
+
+	// white is set by a kelvin, saturated by any hue or saturation
+	white, saturated := false, false
+
+	for _, field := range fields {
+		if hue, ok := namedColors[field]; ok {
+			color.Hue = degrees(hue)
+			color.Saturation = 65535
+			saturated = true
+			continue
+		}
+
+		if field == "white" {
+			color.Saturation = 0
+			saturated = true
+			continue
+		}
+
+		if strings.HasPrefix(field, "#") {
+			hsbk, err := parseRGB(field[1:])
+
+			if err != nil {
+				return color, err
+			}
+
+			hsbk.Kelvin = color.Kelvin
+			color = hsbk
+			saturated = true
+			continue
+		}
+
+		parts := strings.SplitN(field, ":", 2)
+
+		if len(parts) != 2 {
+			return color, fmt.Errorf("unknown color %q", field)
+		}
+
+		value, err := strconv.ParseFloat(parts[1], 64)
+
+		if err != nil {
+			return color, fmt.Errorf("invalid %s %q", parts[0], parts[1])
+		}
+
+		switch parts[0] {
+		case "hue":
+			color.Hue = degrees(value)
+			saturated = true
+		case "saturation":
+			color.Saturation = fraction(value)
+			saturated = true
+		case "brightness":
+			color.Brightness = fraction(value)
+		case "kelvin":
+			if value < 1500 || value > 9000 {
+				return color, fmt.Errorf("kelvin %q out of range 1500..9000", parts[1])
+			}
+			color.Kelvin = uint16(value)
+			white = true
+		default:
+			return color, fmt.Errorf("unknown color component %q", parts[0])
+		}
+	}
+
+	if white && !saturated {
+		color.Saturation = 0
+	}
+
+	return color, nil
+}
+
+// parseBrightness parses a brightness from 0 to 1 or a percentage
+func parseBrightness(s string) (uint16, error) {
+	percent := strings.HasSuffix(s, "%")
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid brightness %q", s)
+	}
+
+	if percent {
+		value /= 100
+	}
+
+	if value < 0 || value > 1 {
+		return 0, fmt.Errorf("brightness %q out of range", s)
+	}
+
+	return fraction(value), nil
+}
+
+func parseRGB(s string) (golifx.HSBK, error) {
+	rgb, err := strconv.ParseUint(s, 16, 32)
+
+	if err != nil || len(s) != 6 {
+		return golifx.HSBK{}, fmt.Errorf("invalid RGB color %q", "#"+s)
+	}
+
+	r := float64(rgb>>16&0xFF) / 255
+	g := float64(rgb>>8&0xFF) / 255
+	b := float64(rgb&0xFF) / 255
+
+	max := maxFloat(r, g, b)
+	min := -maxFloat(-r, -g, -b)
+	delta := max - min
+
+	var hue, saturation float64
+
+	switch {
+	case delta == 0:
+	case max == r:
+		hue = 60 * (g - b) / delta
+	case max == g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+
+	if hue < 0 {
+		hue += 360
+	}
+
+	if max > 0 {
+		saturation = delta / max
+	}
+
+	return golifx.HSBK{
+		Hue:        degrees(hue),
+		Saturation: fraction(saturation),
+		Brightness: fraction(max),
+	}, nil
+}
+
+func maxFloat(values ...float64) float64 {
+	max := values[0]
+
+	for _, value := range values[1:] {
+		if value > max {
+			max = value
+		}
+	}
+
+	return max
+}
+
+// degrees converts a hue in degrees to the protocol range
+func degrees(hue float64) uint16 {
+	for hue >= 360 {
+		hue -= 360
+	}
+	return uint16(hue / 360 * 65536)
+}
+
+// fraction converts a value from 0 to 1 to the protocol range
+func fraction(value float64) uint16 {
+	if value <= 0 {
+		return 0
+	}
+	if value >= 1 {
+		return 65535
+	}
+	return uint16(value * 65535)
+}
+
+// colorJSON is a color as printed by the JSON output
+type colorJSON struct {
+	Hue        float64 `json:"hue"`
+	Saturation float64 `json:"saturation"`
+	Brightness float64 `json:"brightness"`
+	Kelvin     uint16  `json:"kelvin"`
+}
+
+func toColorJSON(color *golifx.HSBK) *colorJSON {
+	if color == nil {
+		return nil
+	}
+
+	return &colorJSON{
+		Hue:        round(float64(color.Hue) * 360 / 65536),
+		Saturation: round(float64(color.Saturation) / 65535),
+		Brightness: round(float64(color.Brightness) / 65535),
+		Kelvin:     color.Kelvin,
+	}
+}
+
+func (c *colorJSON) String() string {
+	return fmt.Sprintf("hue:%g saturation:%g brightness:%g kelvin:%d", c.Hue, c.Saturation, c.Brightness, c.Kelvin)
+}
+
+// round keeps two decimals
+func round(value float64) float64 {
+	return float64(int(value*100+0.5)) / 100
+}
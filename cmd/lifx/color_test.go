@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/2tvenom/golifx"
+)
+
+func TestParseColor(t *testing.T) {
+	current := golifx.HSBK{Hue: 100, Saturation: 200, Brightness: 300, Kelvin: 3500}
+	red := degrees(0)
+
+	tests := []struct {
+		s    string
+		want golifx.HSBK
+	}{
+		{"red", golifx.HSBK{Hue: red, Saturation: 65535, Brightness: 300, Kelvin: 3500}},
+		{"kelvin:2700", golifx.HSBK{Hue: 100, Saturation: 0, Brightness: 300, Kelvin: 2700}},
+		{"red kelvin:2700", golifx.HSBK{Hue: red, Saturation: 65535, Brightness: 300, Kelvin: 2700}},
+		{"kelvin:2700 red", golifx.HSBK{Hue: red, Saturation: 65535, Brightness: 300, Kelvin: 2700}},
+		{"kelvin:2700 saturation:0.5", golifx.HSBK{Hue: 100, Saturation: 32767, Brightness: 300, Kelvin: 2700}},
+		{"kelvin:2700 hue:0", golifx.HSBK{Hue: red, Saturation: 200, Brightness: 300, Kelvin: 2700}},
+		{"#ff0000 kelvin:2700", golifx.HSBK{Hue: red, Saturation: 65535, Brightness: 65535, Kelvin: 2700}},
+		{"white brightness:1", golifx.HSBK{Hue: 100, Saturation: 0, Brightness: 65535, Kelvin: 3500}},
+	}
+
+	for _, test := range tests {
+		got, err := parseColor(test.s, current)
+
+		if err != nil || got != test.want {
+			t.Errorf("%q: got %+v, %v, want %+v", test.s, got, err, test.want)
+		}
+	}
+
+	for _, s := range []string{"", "magenta", "hue:x", "kelvin:100", "#12345"} {
+		if _, err := parseColor(s, current); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
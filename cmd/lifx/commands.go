@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/2tvenom/golifx"
+)
+
+var (
+	duration  time.Duration
+	waveform  string
+	period    time.Duration
+	cycles    float64
+	skew      float64
+	transient bool
+)
+
+// waveforms are the names accepted by the waveform command
+var waveforms = map[string]uint8{
+	"saw":       golifx.WAVEFORM_SAW,
+	"sine":      golifx.WAVEFORM_SINE,
+	"half_sine": golifx.WAVEFORM_HALF_SINE,
+	"triangle":  golifx.WAVEFORM_TRIANGLE,
+	"pulse":     golifx.WAVEFORM_PULSE,
+}
+
+// errEchoMismatch is reported when a device echoes other bytes than sent
+var errEchoMismatch = errors.New("echo response does not match the request")
+
+func init() {
+	commands["list"] = &command{
+		usage: "list [selector]",
+		help:  "List the devices with their power and color.",
+		run:   list,
+	}
+
+	commands["on"] = &command{
+		usage: "on [selector]",
+		help:  "Turn the devices on.",
+		flags: durationFlag,
+		run:   power(true),
+	}
+
+	commands["off"] = &command{
+		usage: "off [selector]",
+		help:  "Turn the devices off.",
+		flags: durationFlag,
+		run:   power(false),
+	}
+
+	commands["color"] = &command{
+		usage:     "color [selector] <color>",
+		help:      "Change the color of the devices.\n\n" + colorHelp,
+		arguments: 1,
+		flags:     durationFlag,
+		run:       setColor,
+	}
+
+	commands["brightness"] = &command{
+		usage:     "brightness [selector] <brightness>",
+		help:      "Change the brightness of the devices, from 0 to 1 or 0% to 100%.",
+		arguments: 1,
+		flags:     durationFlag,
+		run:       setBrightness,
+	}
+
+	commands["info"] = &command{
+		usage: "info [selector]",
+		help:  "Show version, firmware, signal, uptime, group and location of the devices.",
+		run:   info,
+	}
+
+	commands["label"] = &command{
+		usage:     "label <selector> <label>",
+		help:      "Change the label of a single device.",
+		arguments: 1,
+		run:       setLabel,
+	}
+
+	commands["waveform"] = &command{
+		usage:     "waveform [selector] <color>",
+		help:      "Run a waveform effect between the current color and color.\n\n" + colorHelp,
+		arguments: 1,
+		flags:     waveformFlags,
+		run:       setWaveform,
+	}
+
+	commands["echo"] = &command{
+		usage: "echo [selector]",
+		help:  "Send an echo request to the devices and show the round trip time.",
+		run:   echo,
+	}
+}
+
+const colorHelp = `A color is a list of space separated components, the components not
+given keep their current value:
+  red, orange, yellow, green, cyan, blue, purple, pink, white
+  #ff8000          RGB color
+  hue:120          hue in degrees
+  saturation:0.5   saturation from 0 to 1
+  brightness:0.5   brightness from 0 to 1
+  kelvin:2700      white temperature from 1500 to 9000, keeps the
+                   saturation when a hue or saturation is given too`
+
+func durationFlag(fs *flag.FlagSet) {
+	fs.DurationVar(&duration, "duration", 0, "transition time")
+}
+
+func waveformFlags(fs *flag.FlagSet) {
+	fs.StringVar(&waveform, "waveform", "sine", "saw, sine, half_sine, triangle or pulse")
+	fs.DurationVar(&period, "period", time.Second, "duration of a cycle")
+	fs.Float64Var(&cycles, "cycles", 1, "number of cycles")
+	fs.Float64Var(&skew, "skew", 0.5, "time spent on the original color in a pulse cycle, from 0 to 1")
+	fs.BoolVar(&transient, "transient", true, "return to the original color after the effect")
+}
+
+// milliseconds converts a duration flag to the protocol unit
+func milliseconds(d time.Duration) uint32 {
+	return uint32(d / time.Millisecond)
+}
+
+// each runs fn for every bulb in parallel and returns the results in the
+// order of the bulbs. The label is filled in when fn did not set it.
+func each(ctx context.Context, bulbs []*golifx.Bulb, fn func(ctx context.Context, bulb *golifx.Bulb, r *result) error) []*result {
+	results := make([]*result, len(bulbs))
+
+	parallel(bulbs, func(i int, bulb *golifx.Bulb) {
+		r := newResult(bulb)
+
+		if err := fn(ctx, bulb, r); err != nil {
+			r.Error = err.Error()
+		} else {
+			r.OK = true
+		}
+
+		if r.Label == "" {
+			r.Label, _ = bulb.GetLabelContext(ctx)
+		}
+
+		results[i] = r
+	})
+
+	return results
+}
+
+// parallel calls fn for every bulb and waits for all of them
+func parallel(bulbs []*golifx.Bulb, fn func(i int, bulb *golifx.Bulb)) {
+	var wg sync.WaitGroup
+
+	for i, bulb := range bulbs {
+		wg.Add(1)
+		go func(i int, bulb *golifx.Bulb) {
+			defer wg.Done()
+			fn(i, bulb)
+		}(i, bulb)
+	}
+
+	wg.Wait()
+}
+
+func list(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		state, err := bulb.GetColorStateContext(ctx)
+
+		// Relay switches have no light, only a power state
+		var unsupported *golifx.UnsupportedMessageError
+
+		if errors.As(err, &unsupported) {
+			on, err := bulb.GetPowerStateContext(ctx)
+			r.Power = &on
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		r.Label = state.Label
+		r.Power = &state.Power
+		r.Color = toColorJSON(state.Color)
+		return nil
+	}), nil
+}
+
+func power(on bool) func(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	return func(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+		return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+			r.Power = &on
+
+			if duration == 0 {
+				return bulb.SetPowerStateContext(ctx, on)
+			}
+			return bulb.SetPowerDurationStateContext(ctx, on, milliseconds(duration))
+		}), nil
+	}
+}
+
+func setColor(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	// Reject malformed colors before touching any device
+	if _, err := parseColor(args[0], golifx.HSBK{}); err != nil {
+		return nil, err
+	}
+
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		state, err := bulb.GetColorStateContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		color, err := parseColor(args[0], *state.Color)
+
+		if err != nil {
+			return err
+		}
+
+		r.Label = state.Label
+		r.Color = toColorJSON(&color)
+		return bulb.SetColorStateContext(ctx, &color, milliseconds(duration))
+	}), nil
+}
+
+func setBrightness(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	brightness, err := parseBrightness(args[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		state, err := bulb.GetColorStateContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		color := *state.Color
+		color.Brightness = brightness
+
+		r.Label = state.Label
+		r.Color = toColorJSON(&color)
+		return bulb.SetColorStateContext(ctx, &color, milliseconds(duration))
+	}), nil
+}
+
+func info(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		details := &infoJSON{}
+
+		version, err := bulb.GetVersionContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		details.Vendor = version.VendorId
+		details.Product = version.ProductId
+		details.Version = version.Version
+
+		if details.HostFirmware, err = firmware(bulb.GetHostFirmwareContext(ctx)); err != nil {
+			return err
+		}
+
+		if details.WifiFirmware, err = firmware(bulb.GetWifiFirmwareContext(ctx)); err != nil {
+			return err
+		}
+
+		if details.HostSignal, err = signalInfo(bulb.GetStateHostInfoContext(ctx)); err != nil {
+			return err
+		}
+
+		if details.WifiSignal, err = signalInfo(bulb.GetWifiInfoContext(ctx)); err != nil {
+			return err
+		}
+
+		state, err := bulb.GetInfoContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		details.Time = time.Unix(0, int64(state.Time)).UTC().Format(time.RFC3339)
+		details.Uptime = state.UpTime.Seconds()
+		details.Downtime = state.Downtime.Seconds()
+
+		group, err := bulb.GetGroupContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		location, err := bulb.GetLocationContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		details.Group = group.Label
+		details.Location = location.Label
+
+		r.Info = details
+		return nil
+	}), nil
+}
+
+func firmware(f *golifx.BulbFirmware, err error) (*firmwareJSON, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &firmwareJSON{
+		Build:   time.Unix(0, int64(f.Build)).UTC().Format(time.RFC3339),
+		Version: fmt.Sprintf("%d.%d", f.Version>>16, f.Version&0xFFFF),
+	}, nil
+}
+
+func signalInfo(s *golifx.BulbSignalInfo, err error) (*signalJSON, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &signalJSON{Signal: s.Signal, Tx: s.Tx, Rx: s.Rx}, nil
+}
+
+func setLabel(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	if len(bulbs) != 1 {
+		return nil, fmt.Errorf("selector matches %d devices, a label is set on a single one", len(bulbs))
+	}
+
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		r.Label = args[0]
+		return bulb.SetLabelContext(ctx, args[0])
+	}), nil
+}
+
+func setWaveform(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	shape, ok := waveforms[strings.ToLower(waveform)]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown waveform %q", waveform)
+	}
+
+	if skew < 0 || skew > 1 {
+		return nil, fmt.Errorf("skew %g out of range 0..1", skew)
+	}
+
+	if _, err := parseColor(args[0], golifx.HSBK{}); err != nil {
+		return nil, err
+	}
+
+	// The skew ratio is signed, 0 maps to the lowest value
+	skewRatio := int16(skew*65535 - 32768)
+
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		state, err := bulb.GetColorStateContext(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		color, err := parseColor(args[0], *state.Color)
+
+		if err != nil {
+			return err
+		}
+
+		r.Label = state.Label
+		r.Color = toColorJSON(&color)
+		_, err = bulb.SetWaveformContext(ctx, transient, &color, milliseconds(period), float32(cycles), skewRatio, shape)
+		return err
+	}), nil
+}
+
+func echo(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error) {
+	return each(ctx, bulbs, func(ctx context.Context, bulb *golifx.Bulb, r *result) error {
+		request := make([]byte, 8)
+		start := time.Now()
+		binary.LittleEndian.PutUint64(request, uint64(start.UnixNano()))
+
+		response, err := bulb.EchoRequestContext(ctx, request)
+
+		if err != nil {
+			return err
+		}
+
+		if !bytes.HasPrefix(response, request) {
+			return errEchoMismatch
+		}
+
+		rtt := float64(time.Since(start)) / float64(time.Millisecond)
+		r.RTT = &rtt
+		return nil
+	}), nil
+}
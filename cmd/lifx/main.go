@@ -0,0 +1,231 @@
+// Command lifx controls the LIFX devices on the local network.
+//
+//	lifx list
+//	lifx on kitchen -duration 2s
+//	lifx color group:Bedroom "blue brightness:0.3"
+//	lifx info mac:d0:73:d5:01:02:03 -json
+//
+// Selectors pick devices by label, MAC address, group or location, see
+// parseSelector. With -json every command prints a JSON array with one
+// object per device, the exit status is 1 when any device failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/2tvenom/golifx"
+)
+
+type (
+	// command is a subcommand, run is called with the selected bulbs and
+	// the arguments left after the selector
+	command struct {
+		usage string
+		help  string
+		// arguments is the number of arguments after the selector
+		arguments int
+		flags     func(fs *flag.FlagSet)
+		run       func(ctx context.Context, bulbs []*golifx.Bulb, args []string) ([]*result, error)
+	}
+
+	// options are the flags every command accepts
+	options struct {
+		json      bool
+		timeout   time.Duration
+		broadcast string
+		port      int
+	}
+)
+
+var global options
+
+var commands = map[string]*command{}
+
+// order is the order commands are listed in the usage
+var order = []string{"list", "on", "off", "color", "brightness", "info", "label", "waveform", "echo"}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lifx: unknown command %q\n", args[0])
+		usage()
+		return 2
+	}
+
+	fs := flag.NewFlagSet("lifx "+args[0], flag.ContinueOnError)
+	fs.BoolVar(&global.json, "json", false, "print JSON")
+	fs.DurationVar(&global.timeout, "timeout", time.Second, "time to wait for devices to answer discovery")
+	fs.StringVar(&global.broadcast, "broadcast", "", "broadcast address, every interface if empty")
+	fs.IntVar(&global.port, "port", 56700, "UDP port of the devices")
+
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: lifx %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.help)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseFlags(fs, args[1:])
+
+	if err == flag.ErrHelp {
+		return 2
+	}
+
+	if err != nil {
+		return 2
+	}
+
+	// The selector is optional for commands without further arguments
+	selector := ""
+
+	if len(positional) > cmd.arguments {
+		selector = positional[0]
+		positional = positional[1:]
+	}
+
+	if len(positional) != cmd.arguments {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newClient()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+		return 2
+	}
+
+	defer client.Close()
+
+	bulbs, err := discover(ctx, client, parseSelector(selector))
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+		return 1
+	}
+
+	results, err := cmd.run(ctx, bulbs, positional)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+		return 1
+	}
+
+	if err := output(args[0], results); err != nil {
+		fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+		return 1
+	}
+
+	for _, r := range results {
+		if !r.OK {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// parseFlags parses flags placed before, between and after the positional
+// arguments, which the flag package stops at
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func newClient() (*golifx.Client, error) {
+	options := []golifx.ClientOption{golifx.WithPort(global.port)}
+
+	if global.broadcast != "" {
+		ip := net.ParseIP(global.broadcast)
+
+		if ip == nil {
+			return nil, fmt.Errorf("invalid broadcast address %q", global.broadcast)
+		}
+
+		options = append(options, golifx.WithBroadcastAddress(ip))
+	}
+
+	return golifx.NewClient(options...), nil
+}
+
+// discover returns the bulbs answering discovery that match the selector,
+// sorted by MAC address
+func discover(ctx context.Context, client *golifx.Client, s selector) ([]*golifx.Bulb, error) {
+	lookup, cancel := context.WithTimeout(ctx, global.timeout)
+	defer cancel()
+
+	bulbs, err := client.LookupBulbsContext(lookup)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bulbs = s.filter(ctx, bulbs)
+
+	if len(bulbs) == 0 {
+		return nil, fmt.Errorf("no devices found")
+	}
+
+	sort.Slice(bulbs, func(i, j int) bool {
+		return bulbs[i].MacAddress() < bulbs[j].MacAddress()
+	})
+
+	return bulbs, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: lifx <command> [selector] [arguments] [flags]\n\nCommands:\n")
+
+	for _, name := range order {
+		fmt.Fprintf(os.Stderr, "  %-36s %s\n", commands[name].usage, strings.SplitN(commands[name].help, "\n", 2)[0])
+	}
+
+	fmt.Fprintf(os.Stderr, `
+Selectors:
+  all                  every device, the default
+  Kitchen              device with the label or MAC address
+  label:Kitchen        device with the label
+  mac:d0:73:d5:01:02:03
+  group:Bedroom        devices in the group
+  location:Home        devices in the location
+
+Run "lifx <command> -h" for the flags of a command.
+`)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/2tvenom/golifx"
+)
+
+type (
+	// result is the outcome of a command for one device, the JSON output is
+	// an array of them
+	result struct {
+		MAC   string     `json:"mac"`
+		IP    string     `json:"ip"`
+		Label string     `json:"label"`
+		OK    bool       `json:"ok"`
+		Error string     `json:"error,omitempty"`
+		Power *bool      `json:"power,omitempty"`
+		Color *colorJSON `json:"color,omitempty"`
+		Info  *infoJSON  `json:"info,omitempty"`
+		RTT   *float64   `json:"rtt_ms,omitempty"`
+	}
+
+	infoJSON struct {
+		Vendor       uint32        `json:"vendor"`
+		Product      uint32        `json:"product"`
+		Version      uint32        `json:"version"`
+		HostFirmware *firmwareJSON `json:"host_firmware"`
+		WifiFirmware *firmwareJSON `json:"wifi_firmware"`
+		HostSignal   *signalJSON   `json:"host"`
+		WifiSignal   *signalJSON   `json:"wifi"`
+		Time         string        `json:"time"`
+		Uptime       float64       `json:"uptime"`
+		Downtime     float64       `json:"downtime"`
+		Group        string        `json:"group"`
+		Location     string        `json:"location"`
+	}
+
+	firmwareJSON struct {
+		Build   string `json:"build"`
+		Version string `json:"version"`
+	}
+
+	signalJSON struct {
+		Signal float32 `json:"signal"`
+		Tx     uint32  `json:"tx"`
+		Rx     uint32  `json:"rx"`
+	}
+)
+
+func newResult(bulb *golifx.Bulb) *result {
+	r := &result{MAC: bulb.MacAddress()}
+
+	if addr, ok := bulb.IP().(*net.UDPAddr); ok && addr != nil {
+		r.IP = addr.IP.String()
+	}
+
+	return r
+}
+
+// output prints the results of the command to stdout, failures are printed
+// to stderr unless the output is JSON
+func output(name string, results []*result) error {
+	if global.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	switch name {
+	case "list":
+		fmt.Fprintln(w, "MAC\tIP\tLABEL\tPOWER\tCOLOR")
+	case "echo":
+		fmt.Fprintln(w, "MAC\tIP\tLABEL\tRTT")
+	case "info":
+	default:
+		fmt.Fprintln(w, "MAC\tIP\tLABEL\tSTATE")
+	}
+
+	for _, r := range results {
+		if !r.OK {
+			fmt.Fprintf(os.Stderr, "lifx: %s %s: %s\n", r.MAC, r.Label, r.Error)
+			continue
+		}
+
+		switch name {
+		case "list":
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.MAC, r.IP, r.Label, r.powerString(), r.colorString())
+		case "echo":
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.1fms\n", r.MAC, r.IP, r.Label, *r.RTT)
+		case "info":
+			printInfo(w, r)
+		default:
+			state := "ok"
+			if r.Power != nil {
+				state = r.powerString()
+			} else if r.Color != nil {
+				state = r.colorString()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.MAC, r.IP, r.Label, state)
+		}
+	}
+
+	return w.Flush()
+}
+
+func (r *result) powerString() string {
+	if r.Power == nil {
+		return "-"
+	}
+	if *r.Power {
+		return "on"
+	}
+	return "off"
+}
+
+// colorString returns "-" for devices without a light
+func (r *result) colorString() string {
+	if r.Color == nil {
+		return "-"
+	}
+	return r.Color.String()
+}
+
+func printInfo(w *tabwriter.Writer, r *result) {
+	i := r.Info
+
+	fmt.Fprintf(w, "%s\t%s\n", r.MAC, r.Label)
+	fmt.Fprintf(w, "  IP:\t%s\n", r.IP)
+	fmt.Fprintf(w, "  Product:\tvendor %d, product %d, version %d\n", i.Vendor, i.Product, i.Version)
+	fmt.Fprintf(w, "  Host firmware:\t%s, built %s\n", i.HostFirmware.Version, i.HostFirmware.Build)
+	fmt.Fprintf(w, "  Wi-Fi firmware:\t%s, built %s\n", i.WifiFirmware.Version, i.WifiFirmware.Build)
+	fmt.Fprintf(w, "  Host signal:\t%g, tx %d, rx %d\n", i.HostSignal.Signal, i.HostSignal.Tx, i.HostSignal.Rx)
+	fmt.Fprintf(w, "  Wi-Fi signal:\t%g, tx %d, rx %d\n", i.WifiSignal.Signal, i.WifiSignal.Tx, i.WifiSignal.Rx)
+	fmt.Fprintf(w, "  Time:\t%s\n", i.Time)
+	fmt.Fprintf(w, "  Uptime:\t%s\n", seconds(i.Uptime))
+	fmt.Fprintf(w, "  Downtime:\t%s\n", seconds(i.Downtime))
+	fmt.Fprintf(w, "  Group:\t%s\n", i.Group)
+	fmt.Fprintf(w, "  Location:\t%s\n", i.Location)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Second)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/2tvenom/golifx"
+)
+
+// selector picks bulbs by label, MAC address, group or location. A bare
+// value matches labels and MAC addresses, "label:", "mac:", "group:" and
+// "location:" restrict the match. Empty or "all" selects every bulb.
+// Labels, groups and locations are matched case insensitively.
+type selector struct {
+	kind  string
+	value string
+}
+
+func parseSelector(s string) selector {
+	if s == "" || s == "all" {
+		return selector{kind: "all"}
+	}
+
+	if i := strings.Index(s, ":"); i > 0 {
+		switch kind := s[:i]; kind {
+		case "label", "mac", "group", "location":
+			return selector{kind: kind, value: s[i+1:]}
+		}
+	}
+
+	return selector{value: s}
+}
+
+// filter returns the bulbs matching the selector
+func (s selector) filter(ctx context.Context, bulbs []*golifx.Bulb) []*golifx.Bulb {
+	if s.kind == "all" {
+		return bulbs
+	}
+
+	matches := make([]bool, len(bulbs))
+
+	parallel(bulbs, func(i int, bulb *golifx.Bulb) {
+		matches[i] = s.matches(ctx, bulb)
+	})
+
+	selected := []*golifx.Bulb{}
+
+	for i, bulb := range bulbs {
+		if matches[i] {
+			selected = append(selected, bulb)
+		}
+	}
+
+	return selected
+}
+
+func (s selector) matches(ctx context.Context, bulb *golifx.Bulb) bool {
+	switch s.kind {
+	case "mac":
+		return s.matchesMAC(bulb)
+	case "label":
+		return s.matchesLabel(ctx, bulb)
+	case "group":
+		group, err := bulb.GetGroupContext(ctx)
+		return err == nil && strings.EqualFold(group.Label, s.value)
+	case "location":
+		location, err := bulb.GetLocationContext(ctx)
+		return err == nil && strings.EqualFold(location.Label, s.value)
+	}
+
+	return s.matchesMAC(bulb) || s.matchesLabel(ctx, bulb)
+}
+
+func (s selector) matchesMAC(bulb *golifx.Bulb) bool {
+	mac, err := golifx.ParseMAC(s.value)
+	return err == nil && mac == bulb.HardwareAddress()
+}
+
+func (s selector) matchesLabel(ctx context.Context, bulb *golifx.Bulb) bool {
+	label, err := bulb.GetLabelContext(ctx)
+	return err == nil && strings.EqualFold(label, s.value)
+}
@@ -0,0 +1,145 @@
+package golifx
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// testDevice is a minimal bulb answering service, power and echo requests
+// on a transport, for the tests of this package which cannot use lifxtest
+type testDevice struct {
+	transport Transport
+	mac       uint64
+
+	mu       sync.Mutex
+	power    uint16
+	received map[uint16]int
+}
+
+// _TEST_MAC is d0:73:d5:00:00:01
+const _TEST_MAC = 0x010000d573d0
+
+// newTestDevice starts a device answering on the transport, it is closed
+// when the test ends
+func newTestDevice(t testing.TB, transport Transport) *testDevice {
+	d := &testDevice{
+		transport: transport,
+		mac:       _TEST_MAC,
+		received:  map[uint16]int{},
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		d.serve()
+	}()
+
+	t.Cleanup(func() {
+		transport.Close()
+		<-done
+	})
+
+	return d
+}
+
+// listenTestDevice starts a device on a loopback UDP socket
+func listenTestDevice(t testing.TB) (*testDevice, *net.UDPAddr) {
+	transport, err := NewUDPTransport(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newTestDevice(t, transport), transport.LocalAddr().(*net.UDPAddr)
+}
+
+func (d *testDevice) serve() {
+	buff := make([]byte, _MAX_PACKET_SIZE)
+
+	for {
+		n, addr, err := d.transport.ReadFrom(buff)
+
+		if err != nil {
+			if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
+				continue
+			}
+			return
+		}
+
+		in := &protocol.Packet{}
+
+		if in.UnmarshalBinary(buff[:n]) != nil {
+			continue
+		}
+
+		if !in.Tagged && in.Target != 0 && in.Target != d.mac {
+			continue
+		}
+
+		for _, reply := range d.handle(in) {
+			d.reply(in, reply, addr)
+		}
+	}
+}
+
+func (d *testDevice) handle(in *protocol.Packet) []protocol.Payload {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.received[in.Type]++
+
+	replies := []protocol.Payload{}
+
+	if in.AckRequired {
+		replies = append(replies, &protocol.DeviceAcknowledgement{})
+	}
+
+	switch payload := in.Payload.(type) {
+	case *protocol.DeviceGetService:
+		port := uint32(d.transport.LocalAddr().(*net.UDPAddr).Port)
+		replies = append(replies, &protocol.DeviceStateService{Service: protocol.DeviceServiceUDP, Port: port})
+	case *protocol.DeviceGetPower:
+		replies = append(replies, &protocol.DeviceStatePower{Level: d.power})
+	case *protocol.DeviceSetPower:
+		d.power = payload.Level
+
+		if in.ResRequired {
+			replies = append(replies, &protocol.DeviceStatePower{Level: d.power})
+		}
+	case *protocol.DeviceEchoRequest:
+		replies = append(replies, &protocol.DeviceEchoResponse{Echoing: payload.Echoing})
+	}
+
+	return replies
+}
+
+// reply sends the payload to addr as an answer to in
+func (d *testDevice) reply(in *protocol.Packet, payload protocol.Payload, addr net.Addr) {
+	out := protocol.NewPacket(payload)
+	out.Source = in.Source
+	out.Target = d.mac
+	out.Sequence = in.Sequence
+
+	if data, err := out.MarshalBinary(); err == nil {
+		d.transport.WriteTo(data, addr)
+	}
+}
+
+// count returns how many messages of the type the device received
+func (d *testDevice) count(messageType uint16) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.received[messageType]
+}
+
+func (d *testDevice) powerLevel() uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.power
+}
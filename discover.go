@@ -0,0 +1,212 @@
+package golifx
+
+import (
+	"context"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+type (
+	// DiscoveryEventType tells what happened to a bulb during discovery
+	DiscoveryEventType int
+
+	// DiscoveryEvent is emitted by Discover
+	DiscoveryEvent struct {
+		Type DiscoveryEventType
+		Bulb *Bulb
+	}
+
+	// DiscoverOptions configures Discover
+	DiscoverOptions struct {
+		// Interval re-broadcasts GetService periodically to pick up bulbs
+		// joining later, zero broadcasts once
+		Interval time.Duration
+		// LostAfter is how long a bulb may stay silent before BulbLost is
+		// emitted, zero means three intervals. Only used with Interval.
+		LostAfter time.Duration
+	}
+
+	discovered struct {
+		bulb     *Bulb
+		lastSeen time.Time
+		lost     bool
+	}
+)
+
+const (
+	// BulbFound is emitted the first time a bulb answers and when a lost
+	// bulb answers again
+	BulbFound DiscoveryEventType = iota
+	// BulbLost is emitted when a previously seen bulb stops answering
+	BulbLost
+)
+
+func (t DiscoveryEventType) String() string {
+	switch t {
+	case BulbFound:
+		return "found"
+	case BulbLost:
+		return "lost"
+	}
+	return "unknown"
+}
+
+// Discover broadcasts GetService and emits every bulb as soon as it answers.
+// Bulbs are deduplicated by MAC address. Events are buffered so a slow
+// reader does not hold up the probes and lost bulb detection. The channel
+// is closed when ctx is done.
+func (c *Client) Discover(ctx context.Context, options DiscoverOptions) (<-chan DiscoveryEvent, error) {
+	msg := c.makeMessage()
+	msg.tagged = true
+	msg._type = protocol.TypeDeviceGetService
+
+	req, err := c.conn.register(msg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw := msg.ReadRaw()
+
+	if err = c.broadcast(ctx, raw); err != nil {
+		c.conn.unregister(msg, req)
+		return nil, err
+	}
+
+	events := make(chan DiscoveryEvent, _MAX_PENDING_RESPONSES)
+
+	go func() {
+		defer close(events)
+		defer c.conn.unregister(msg, req)
+
+		emit := func(t DiscoveryEventType, bulb *Bulb) bool {
+			select {
+			case events <- DiscoveryEvent{Type: t, Bulb: bulb}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var tick <-chan time.Time
+
+		lostAfter := options.LostAfter
+
+		if options.Interval > 0 {
+			ticker := time.NewTicker(options.Interval)
+			defer ticker.Stop()
+			tick = ticker.C
+
+			if lostAfter <= 0 {
+				lostAfter = options.Interval * 3
+			}
+		}
+
+		seen := map[uint64]*discovered{}
+
+		for {
+			select {
+			case m, ok := <-req.responses:
+				if !ok {
+					return
+				}
+
+				bulb := c.bulbFromService(m)
+
+				if bulb == nil {
+					continue
+				}
+
+				bulb = c.addBulb(bulb)
+
+				d, ok := seen[bulb.hardwareAddress]
+
+				if !ok {
+					seen[bulb.hardwareAddress] = &discovered{bulb: bulb, lastSeen: time.Now()}
+
+					if !emit(BulbFound, bulb) {
+						return
+					}
+					continue
+				}
+
+				d.lastSeen = time.Now()
+
+				if d.lost {
+					d.lost = false
+
+					if !emit(BulbFound, d.bulb) {
+						return
+					}
+				}
+			case <-tick:
+				c.broadcast(ctx, raw)
+
+				for _, d := range seen {
+					if d.lost || time.Since(d.lastSeen) < lostAfter {
+						continue
+					}
+
+					d.lost = true
+
+					if !emit(BulbLost, d.bulb) {
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// broadcast sends the message to every broadcast target once the broadcast
+// rate limit allows it, it fails only when no target could be reached
+func (c *Client) broadcast(ctx context.Context, raw []byte) error {
+	err := c.limiter.waitBroadcast(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	sent := false
+
+	for _, target := range c.conn.broadcastTargets() {
+		if writeErr := c.conn.write(target.link, raw, target.addr); writeErr != nil {
+			err = writeErr
+			continue
+		}
+		sent = true
+	}
+
+	if sent {
+		return nil
+	}
+	return err
+}
+
+// bulbFromService builds a bulb from a StateService reply, nil is returned
+// for services other than UDP. The bulb is not registered with the client.
+func (c *Client) bulbFromService(msg *message) *Bulb {
+	if msg._type != protocol.TypeDeviceStateService {
+		return nil
+	}
+
+	service := &protocol.DeviceStateService{}
+
+	if err := service.UnmarshalBinary(msg.payout); err != nil || service.Service != protocol.DeviceServiceUDP {
+		return nil
+	}
+
+	bulb := &Bulb{client: c}
+	bulb.hardwareAddress = msg.target
+	bulb.ipAddress = msg.addr
+	bulb.iface = msg.iface
+	bulb.link = msg.link
+	bulb.port = service.Port
+
+	return bulb
+}
@@ -0,0 +1,95 @@
+package golifx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// UnsupportedMessageError is returned, wrapped in an *Error, when a bulb
+// answers with StateUnhandled because it cannot handle the message type,
+// e.g. multizone messages sent to a plain bulb. Use errors.As to get it.
+type UnsupportedMessageError struct {
+	Bulb        *Bulb
+	MessageType uint16
+}
+
+func (e *UnsupportedMessageError) Error() string {
+	return fmt.Sprintf("Bulb %s does not support %s", e.Bulb.MacAddress(), protocol.TypeName(e.MessageType))
+}
+
+// ErrTimeout is returned, wrapped in an *Error, when the bulb does not reply
+// before the deadline
+var ErrTimeout = errors.New("Request timed out")
+
+// Error describes a failed request to a bulb. Err is the cause, one of
+// ErrTimeout, ErrIncorrectResponseType, ErrHardwareAddressMismatch, an
+// *UnsupportedMessageError, a *protocol.DecodeError, a context error or a
+// network error, and can be tested with errors.Is and errors.As. Attempts
+// is zero when the reply arrived but could not be decoded.
+type Error struct {
+	MAC          string
+	IP           net.Addr
+	RequestType  uint16
+	ExpectedType uint16
+	ReceivedType uint16
+	Attempts     int
+	Err          error
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("Bulb %s", e.MAC)
+
+	if e.IP != nil {
+		s += fmt.Sprintf(" (%s)", e.IP)
+	}
+
+	s += fmt.Sprintf(": %s", protocol.TypeName(e.RequestType))
+
+	if e.ReceivedType != 0 {
+		s += fmt.Sprintf(" expected %s, received %s", protocol.TypeName(e.ExpectedType), protocol.TypeName(e.ReceivedType))
+	}
+
+	if e.Attempts > 1 {
+		s += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+
+	return s + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports timeouts as io.EOF too, which is what they were returned as
+// before, and timeouts waiting for an acknowledgement as ErrNoResponse
+func (e *Error) Is(target error) bool {
+	switch target {
+	case io.EOF:
+		return e.IsTimeout()
+	case ErrNoResponse:
+		return e.IsTimeout() && e.ExpectedType == protocol.TypeDeviceAcknowledgement
+	}
+	return false
+}
+
+// IsTimeout reports whether the bulb did not answer in time
+func (e *Error) IsTimeout() bool {
+	return errors.Is(e.Err, ErrTimeout) || errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// newError builds the error of a request sent to the bulb
+func (b *Bulb) newError(msg *message, expected uint16, attempts int, err error) *Error {
+	return &Error{
+		MAC:          b.MacAddress(),
+		IP:           b.IP(),
+		RequestType:  msg._type,
+		ExpectedType: expected,
+		Attempts:     attempts,
+		Err:          err,
+	}
+}
@@ -0,0 +1,85 @@
+package golifx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		err    *Error
+		target error
+		want   bool
+	}{
+		{&Error{Err: ErrTimeout}, io.EOF, true},
+		{&Error{Err: context.DeadlineExceeded}, io.EOF, true},
+		{&Error{Err: context.Canceled}, io.EOF, false},
+		{&Error{Err: ErrTimeout, ExpectedType: protocol.TypeDeviceAcknowledgement}, ErrNoResponse, true},
+		{&Error{Err: ErrTimeout, ExpectedType: protocol.TypeDeviceStatePower}, ErrNoResponse, false},
+		{&Error{Err: ErrIncorrectResponseType, ExpectedType: protocol.TypeDeviceAcknowledgement}, ErrNoResponse, false},
+		{&Error{Err: ErrIncorrectResponseType}, ErrIncorrectResponseType, true},
+	}
+
+	for _, test := range tests {
+		if got := errors.Is(test.err, test.target); got != test.want {
+			t.Errorf("errors.Is(%v, %v) = %t, want %t", test.err, test.target, got, test.want)
+		}
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	client := NewClient()
+	bulb := client.NewBulb(net.IPv4(127, 0, 0, 1), _TEST_MAC)
+
+	set := makeMessage()
+	set._type = protocol.TypeDeviceSetPower
+
+	reply := func(messageType uint16, target uint64) *message {
+		m := makeMessage()
+		m._type = messageType
+		m.target = target
+		return m
+	}
+
+	if _, err := bulb.checkResponse(set, protocol.TypeDeviceAcknowledgement, 1, reply(protocol.TypeDeviceAcknowledgement, _TEST_MAC)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reply of another type to an acknowledged request is not a missing
+	// acknowledgement
+	_, err := bulb.checkResponse(set, protocol.TypeDeviceAcknowledgement, 2, reply(protocol.TypeLightState, _TEST_MAC))
+
+	var e *Error
+
+	if !errors.Is(err, ErrIncorrectResponseType) || errors.Is(err, ErrNoResponse) || !errors.As(err, &e) {
+		t.Fatalf("got %v, want ErrIncorrectResponseType", err)
+	}
+
+	if e.Attempts != 2 || e.ReceivedType != protocol.TypeLightState || e.MAC != "d0:73:d5:00:00:01" {
+		t.Fatalf("got %+v", e)
+	}
+
+	_, err = bulb.checkResponse(set, protocol.TypeDeviceAcknowledgement, 1, reply(protocol.TypeDeviceStateUnhandled, _TEST_MAC))
+
+	var unsupported *UnsupportedMessageError
+
+	if !errors.As(err, &e) || !errors.As(err, &unsupported) || unsupported.MessageType != protocol.TypeDeviceSetPower {
+		t.Fatalf("got %v, want an *Error wrapping UnsupportedMessageError", err)
+	}
+
+	// Any device answers a tagged message, it has to be the bulb
+	get := makeMessage()
+	get.tagged = true
+	get._type = protocol.TypeDeviceGetService
+
+	_, err = bulb.checkResponse(get, protocol.TypeDeviceStateService, 1, reply(protocol.TypeDeviceStateService, _TEST_MAC+1))
+
+	if !errors.Is(err, ErrHardwareAddressMismatch) || !errors.As(err, &e) {
+		t.Fatalf("got %v, want ErrHardwareAddressMismatch", err)
+	}
+}
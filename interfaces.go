@@ -0,0 +1,190 @@
+package golifx
+
+import (
+	"net"
+	"time"
+)
+
+type (
+	// broadcastTarget is a broadcast address and the key of the link it is
+	// reachable through
+	broadcastTarget struct {
+		link string
+		addr *net.UDPAddr
+	}
+
+	// interfaceNet is an IPv4 address of a network interface with the mask
+	// of its subnet
+	interfaceNet struct {
+		name    string
+		network *net.IPNet
+	}
+)
+
+// _INTERFACE_REFRESH is how long the list of interfaces is kept before it
+// is read again
+const _INTERFACE_REFRESH = time.Second * 30
+
+// listInterfaceNets lists the networks discovery messages are broadcast to
+var listInterfaceNets = systemInterfaceNets
+
+// broadcastTargets returns where discovery messages are sent: the directed
+// broadcast address of every IPv4 subnet the host is attached to, or the
+// configured broadcast address when interface discovery is off or finds
+// nothing
+func (c *connection) broadcastTargets() []broadcastTarget {
+	if c.interfaces {
+		if targets := c.interfaceTargets(); len(targets) > 0 {
+			return targets
+		}
+	}
+
+	return []broadcastTarget{{addr: c.broadcastAddr()}}
+}
+
+// broadcastAddrFor returns the broadcast address of the subnet the link is
+// attached to
+func (c *connection) broadcastAddrFor(key string) *net.UDPAddr {
+	if key != "" {
+		c.mu.Lock()
+		l, ok := c.links[key]
+		c.mu.Unlock()
+
+		if ok && l.broadcast != nil {
+			return l.broadcast
+		}
+	}
+
+	return c.broadcastAddr()
+}
+
+func (c *connection) interfaceTargets() []broadcastTarget {
+	targets := []broadcastTarget{}
+
+	for _, l := range c.interfaceLinks() {
+		targets = append(targets, broadcastTarget{link: l.key, addr: l.broadcast})
+	}
+
+	return targets
+}
+
+// interfaceLinks returns a link for every address of the interfaces,
+// opening the links that are missing
+func (c *connection) interfaceLinks() []*link {
+	links := []*link{}
+
+	for _, ifaceNet := range c.interfaceNets() {
+		l, err := c.openLink(ifaceNet.name, &net.UDPAddr{IP: ifaceNet.network.IP})
+
+		if err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		l.network = ifaceNet.network
+		l.broadcast = &net.UDPAddr{IP: directedBroadcast(ifaceNet.network), Port: c.port}
+		c.mu.Unlock()
+
+		links = append(links, l)
+	}
+
+	return links
+}
+
+// linkFor returns the key of the interface link whose subnet contains ip,
+// the default link is used for addresses outside every subnet
+func (c *connection) linkFor(ip net.IP) string {
+	if !c.interfaces {
+		return ""
+	}
+
+	for _, l := range c.interfaceLinks() {
+		if l.network.Contains(ip) {
+			return l.key
+		}
+	}
+
+	return ""
+}
+
+// interfaceNets returns the networks of the interfaces, they are listed
+// again once they are older than _INTERFACE_REFRESH
+func (c *connection) interfaceNets() []interfaceNet {
+	c.mu.Lock()
+	if !c.interfacesListed.IsZero() && time.Since(c.interfacesListed) < _INTERFACE_REFRESH {
+		nets := c.interfaceCache
+		c.mu.Unlock()
+		return nets
+	}
+	c.mu.Unlock()
+
+	nets, err := listInterfaceNets()
+
+	if err != nil {
+		nets = nil
+	}
+
+	c.mu.Lock()
+	c.interfaceCache = nets
+	c.interfacesListed = time.Now()
+	c.mu.Unlock()
+
+	return nets
+}
+
+// systemInterfaceNets returns the IPv4 networks of the interfaces that are
+// up and can broadcast, loopback interfaces are left out
+func systemInterfaceNets() ([]interfaceNet, error) {
+	ifaces, err := net.Interfaces()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nets := []interfaceNet{}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagBroadcast == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+
+			if !ok || ipNet.IP.To4() == nil {
+				continue
+			}
+
+			nets = append(nets, interfaceNet{
+				name:    iface.Name,
+				network: &net.IPNet{IP: ipNet.IP.To4(), Mask: ipNet.Mask},
+			})
+		}
+	}
+
+	return nets, nil
+}
+
+// directedBroadcast returns the last address of an IPv4 subnet
+func directedBroadcast(ipNet *net.IPNet) net.IP {
+	ip := ipNet.IP.To4()
+	mask := ipNet.Mask
+
+	if len(mask) == net.IPv6len {
+		mask = mask[12:]
+	}
+
+	bcast := make(net.IP, net.IPv4len)
+
+	for i := range bcast {
+		bcast[i] = ip[i] | ^mask[i]
+	}
+
+	return bcast
+}
@@ -0,0 +1,112 @@
+package golifx
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// useInterfaceNets makes the client see the networks instead of the
+// interfaces of the host and returns the number of times they were listed
+func useInterfaceNets(t *testing.T, nets ...string) *int {
+	listed := 0
+	ifaceNets := []interfaceNet{}
+
+	for _, s := range nets {
+		ip, network, err := net.ParseCIDR(s)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ifaceNets = append(ifaceNets, interfaceNet{
+			name:    "lo",
+			network: &net.IPNet{IP: ip.To4(), Mask: network.Mask},
+		})
+	}
+
+	listInterfaceNets = func() ([]interfaceNet, error) {
+		listed++
+		return ifaceNets, nil
+	}
+
+	t.Cleanup(func() { listInterfaceNets = systemInterfaceNets })
+
+	return &listed
+}
+
+func TestInterfaceTargets(t *testing.T) {
+	listed := useInterfaceNets(t, "127.0.0.1/8")
+
+	client := NewClient()
+	defer client.Close()
+
+	targets := client.conn.broadcastTargets()
+
+	if len(targets) != 1 || targets[0].link != "lo/127.0.0.1" || targets[0].addr.String() != "127.255.255.255:56700" {
+		t.Fatalf("got targets %+v", targets)
+	}
+
+	// The interfaces are listed again only after the refresh interval
+	client.conn.broadcastTargets()
+
+	if *listed != 1 {
+		t.Fatalf("interfaces listed %d times, want 1", *listed)
+	}
+
+	client.conn.mu.Lock()
+	client.conn.interfacesListed = time.Now().Add(-_INTERFACE_REFRESH)
+	client.conn.mu.Unlock()
+
+	client.conn.broadcastTargets()
+
+	if *listed != 2 {
+		t.Fatalf("interfaces listed %d times after the refresh interval, want 2", *listed)
+	}
+
+	if key := client.conn.linkFor(net.IPv4(127, 1, 2, 3)); key != "lo/127.0.0.1" {
+		t.Fatalf("got link %q for 127.1.2.3", key)
+	}
+
+	if key := client.conn.linkFor(net.IPv4(10, 0, 0, 1)); key != "" {
+		t.Fatalf("got link %q for 10.0.0.1, want the default one", key)
+	}
+}
+
+func TestInterfaceDiscovery(t *testing.T) {
+	// The broadcast address of a /32 is the device address itself
+	useInterfaceNets(t, "127.0.0.1/32")
+
+	d, addr := listenTestDevice(t)
+
+	client := NewClient(WithPort(addr.Port), WithDeadline(100*time.Millisecond))
+	defer client.Close()
+
+	bulbs, err := client.LookupBulbs()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bulbs) != 1 || bulbs[0].Interface() != "lo" || bulbs[0].linkKey() != "lo/127.0.0.1" {
+		t.Fatalf("got %v", bulbs)
+	}
+
+	if err := bulbs[0].SetPowerState(true); err != nil || d.powerLevel() != 65535 {
+		t.Fatalf("got %v, power %d", err, d.powerLevel())
+	}
+}
+
+func TestSystemInterfaceNets(t *testing.T) {
+	nets, err := systemInterfaceNets()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range nets {
+		if n.network.IP.To4() == nil || n.network.IP.IsLoopback() {
+			t.Errorf("%s: %s is not a broadcast IPv4 network", n.name, n.network)
+		}
+	}
+}
@@ -4,80 +4,81 @@ import (
 	"encoding/json"
 )
 
-func (b Bulb) MarshalJSON() ([]byte, error) {
+func (b *Bulb) MarshalJSON() ([]byte, error) {
+	s := b.snapshot()
 	index := map[string]interface{}{
-		"mac":         b.MacAddress(),
-		"ip":          b.ipAddress,
-		"label":       b.label,
-		"power_state": b.powerState,
+		"mac":         s.MacAddress(),
+		"ip":          s.ipAddress,
+		"label":       s.label,
+		"power_state": s.powerState,
 	}
 
-	if b.stateHostInfo != nil {
+	if s.stateHostInfo != nil {
 		index["host_info"] = map[string]interface{}{
-			"signal": b.stateHostInfo.Signal,
-			"rx":     b.stateHostInfo.Rx,
-			"tx":     b.stateHostInfo.Tx,
+			"signal": s.stateHostInfo.Signal,
+			"rx":     s.stateHostInfo.Rx,
+			"tx":     s.stateHostInfo.Tx,
 		}
 	}
 
-	if b.wifiInfo != nil {
+	if s.wifiInfo != nil {
 		index["wifi_info"] = map[string]interface{}{
-			"signal": b.wifiInfo.Signal,
-			"rx":     b.wifiInfo.Rx,
-			"tx":     b.wifiInfo.Tx,
+			"signal": s.wifiInfo.Signal,
+			"rx":     s.wifiInfo.Rx,
+			"tx":     s.wifiInfo.Tx,
 		}
 	}
 
-	if b.version != nil {
+	if s.version != nil {
 		index["version"] = map[string]interface{}{
-			"version":    b.version.Version,
-			"product_id": b.version.ProductId,
-			"vendor_id":  b.version.VendorId,
+			"version":    s.version.Version,
+			"product_id": s.version.ProductId,
+			"vendor_id":  s.version.VendorId,
 		}
 	}
 
-	if b.hostFirmware != nil {
+	if s.hostFirmware != nil {
 		index["host_firmware"] = map[string]interface{}{
-			"build":   b.hostFirmware.Build,
-			"version": b.hostFirmware.Version,
+			"build":   s.hostFirmware.Build,
+			"version": s.hostFirmware.Version,
 		}
 	}
 
-	if b.wifiFirmware != nil {
+	if s.wifiFirmware != nil {
 		index["wifi_firmware"] = map[string]interface{}{
-			"build":   b.wifiFirmware.Build,
-			"version": b.wifiFirmware.Version,
+			"build":   s.wifiFirmware.Build,
+			"version": s.wifiFirmware.Version,
 		}
 	}
 
-	if b.info != nil {
+	if s.info != nil {
 		index["info"] = map[string]interface{}{
-			"downtime": b.info.Downtime.Nanoseconds(),
-			"time":     durationToStrDate(b.info.Time),
-			"uptime":   b.info.UpTime.Seconds(),
+			"downtime": s.info.Downtime.Nanoseconds(),
+			"time":     durationToStrDate(s.info.Time),
+			"uptime":   s.info.UpTime.Seconds(),
 		}
 	}
 
-	if b.location != nil {
+	if s.location != nil {
 		index["location"] = map[string]interface{}{
-			"label":     b.location.Label,
-			"updatedat": durationToStrDate(b.location.UpdatedAt),
+			"label":     s.location.Label,
+			"updatedat": durationToStrDate(s.location.UpdatedAt),
 		}
 	}
 
-	if b.group != nil {
+	if s.group != nil {
 		index["group"] = map[string]interface{}{
-			"label":     b.group.Label,
-			"updatedat": durationToStrDate(b.group.UpdatedAt),
+			"label":     s.group.Label,
+			"updatedat": durationToStrDate(s.group.UpdatedAt),
 		}
 	}
 
-	if b.color != nil {
+	if s.color != nil {
 		index["color"] = map[string]interface{}{
-			"hue":        b.color.Hue,
-			"kelvin":     b.color.Kelvin,
-			"saturation": b.color.Saturation,
-			"brightness": b.color.Brightness,
+			"hue":        s.color.Hue,
+			"kelvin":     s.color.Kelvin,
+			"saturation": s.color.Saturation,
+			"brightness": s.color.Brightness,
 		}
 	}
 
@@ -0,0 +1,615 @@
+package lifxtest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"sync"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+type (
+	// DeviceOptions configures a device added with Server.AddDevice
+	DeviceOptions struct {
+		// MAC is the hardware address in golifx byte order, see
+		// golifx.ParseMAC. Zero picks the next free d0:73:d5 address.
+		MAC      uint64
+		Label    string
+		Group    string
+		Location string
+		// Product is the emulated hardware, ProductColor if empty
+		Product Product
+		Power   bool
+		Color   protocol.LightHsbk
+		// Zones, Tiles and Relays set the initial state of multizone
+		// strips, tiles and switches, missing zones and tiles have Color and
+		// missing relays are off
+		Zones  []protocol.LightHsbk
+		Tiles  [][64]protocol.LightHsbk
+		Relays []uint16
+		// Loss is the probability (0..1) a received packet is ignored
+		Loss float64
+		// Latency delays every reply
+		Latency time.Duration
+		// Unhandled message types are answered with StateUnhandled
+		Unhandled []uint16
+	}
+
+	// Device is a virtual LIFX device. Its state changes with the messages
+	// it receives and can be read and changed by tests at any time.
+	Device struct {
+		server  *Server
+		mac     uint64
+		product Product
+		started time.Time
+
+		mu        sync.Mutex
+		label     string
+		group     string
+		location  string
+		power     uint16
+		color     protocol.LightHsbk
+		zones     []protocol.LightHsbk
+		tiles     [][64]protocol.LightHsbk
+		relays    []uint16
+		loss      float64
+		latency   time.Duration
+		unhandled map[uint16]bool
+		received  map[uint16]int
+	}
+
+	// snapshot is the state other clients are notified about
+	snapshot struct {
+		label string
+		power uint16
+		color protocol.LightHsbk
+	}
+)
+
+func newDevice(server *Server, options DeviceOptions) *Device {
+	if options.Product.Name == "" {
+		options.Product = ProductColor
+	}
+
+	d := &Device{
+		server:    server,
+		mac:       options.MAC,
+		product:   options.Product,
+		started:   time.Now(),
+		label:     options.Label,
+		group:     options.Group,
+		location:  options.Location,
+		power:     powerLevel(options.Power),
+		zones:     make([]protocol.LightHsbk, options.Product.Zones),
+		tiles:     make([][64]protocol.LightHsbk, options.Product.Tiles),
+		relays:    make([]uint16, options.Product.Relays),
+		loss:      options.Loss,
+		latency:   options.Latency,
+		unhandled: map[uint16]bool{},
+		received:  map[uint16]int{},
+	}
+
+	d.color = d.filterColor(options.Color)
+
+	for i := range d.zones {
+		d.zones[i] = d.color
+	}
+
+	for i := range d.tiles {
+		for j := range d.tiles[i] {
+			d.tiles[i][j] = d.color
+		}
+	}
+
+	copy(d.zones, options.Zones)
+	copy(d.tiles, options.Tiles)
+	copy(d.relays, options.Relays)
+
+	for _, messageType := range options.Unhandled {
+		d.unhandled[messageType] = true
+	}
+
+	return d
+}
+
+// MAC returns the hardware address in golifx byte order
+func (d *Device) MAC() uint64 {
+	return d.mac
+}
+
+// Product returns the emulated hardware
+func (d *Device) Product() Product {
+	return d.product
+}
+
+func (d *Device) Label() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.label
+}
+
+func (d *Device) Group() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.group
+}
+
+func (d *Device) Location() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.location
+}
+
+func (d *Device) Power() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.power != 0
+}
+
+func (d *Device) Color() protocol.LightHsbk {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.color
+}
+
+// Zones returns the colors of a multizone strip
+func (d *Device) Zones() []protocol.LightHsbk {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]protocol.LightHsbk(nil), d.zones...)
+}
+
+// Tiles returns the colors of the 64 pixels of every tile
+func (d *Device) Tiles() [][64]protocol.LightHsbk {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([][64]protocol.LightHsbk(nil), d.tiles...)
+}
+
+// Relays returns the power levels of the relays of a switch
+func (d *Device) Relays() []uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]uint16(nil), d.relays...)
+}
+
+// SetPower switches the device as if it was done by another client,
+// the change is sent as set up by Server.Notify and Server.NotifyClients
+func (d *Device) SetPower(on bool) {
+	d.mu.Lock()
+	d.power = powerLevel(on)
+	d.mu.Unlock()
+
+	d.server.notify(d, &protocol.DeviceStatePower{Level: powerLevel(on)})
+}
+
+// SetColor changes the color as if it was done by another client,
+// the change is sent as set up by Server.Notify and Server.NotifyClients
+func (d *Device) SetColor(color protocol.LightHsbk) {
+	d.mu.Lock()
+	d.color = d.filterColor(color)
+	state := d.lightState()
+	d.mu.Unlock()
+
+	d.server.notify(d, state)
+}
+
+// SetLabel renames the device as if it was done by another client,
+// the change is sent as set up by Server.Notify and Server.NotifyClients
+func (d *Device) SetLabel(label string) {
+	d.mu.Lock()
+	d.label = label
+	d.mu.Unlock()
+
+	state := &protocol.DeviceStateLabel{}
+	copy(state.Label[:], label)
+
+	d.server.notify(d, state)
+}
+
+// SetLoss sets the probability (0..1) a received packet is ignored
+func (d *Device) SetLoss(loss float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.loss = loss
+}
+
+// SetLatency sets the delay of every reply
+func (d *Device) SetLatency(latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.latency = latency
+}
+
+// SetUnhandled makes the device answer the message types with StateUnhandled
+func (d *Device) SetUnhandled(messageTypes ...uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.unhandled = map[uint16]bool{}
+
+	for _, messageType := range messageTypes {
+		d.unhandled[messageType] = true
+	}
+}
+
+// Received returns how many messages of the type the device handled,
+// packets lost on purpose are not counted
+func (d *Device) Received(messageType uint16) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.received[messageType]
+}
+
+func (d *Device) state() snapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return snapshot{label: d.label, power: d.power, color: d.color}
+}
+
+// faults returns the loss and latency of the device
+func (d *Device) faults() (float64, time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.loss, d.latency
+}
+
+// handle applies a packet addressed to the device and returns the replies.
+// Sets are acknowledged when asked and answered with their state only when
+// a response is required, gets are always answered.
+func (d *Device) handle(in *protocol.Packet) []protocol.Payload {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.received[in.Type]++
+
+	replies := []protocol.Payload{}
+
+	if in.AckRequired {
+		replies = append(replies, &protocol.DeviceAcknowledgement{})
+	}
+
+	if d.unhandled[in.Type] {
+		return append(replies, &protocol.DeviceStateUnhandled{UnhandledType: in.Type})
+	}
+
+	states, set, ok := d.apply(in.Payload)
+
+	if !ok {
+		return append(replies, &protocol.DeviceStateUnhandled{UnhandledType: in.Type})
+	}
+
+	if !set || in.ResRequired {
+		replies = append(replies, states...)
+	}
+
+	return replies
+}
+
+// apply handles the payload and returns the states it is answered with,
+// set tells whether it changed the device, ok is false for unsupported
+// messages
+func (d *Device) apply(payload protocol.Payload) (states []protocol.Payload, set bool, ok bool) {
+	if states, set, ok = d.applyDevice(payload); ok {
+		return states, set, ok
+	}
+
+	switch {
+	case d.product.HasLight():
+		if states, set, ok = d.applyLight(payload); ok {
+			return states, set, ok
+		}
+	case len(d.relays) > 0:
+		if states, set, ok = d.applyRelay(payload); ok {
+			return states, set, ok
+		}
+	}
+
+	if len(d.zones) > 0 {
+		if states, set, ok = d.applyMultiZone(payload); ok {
+			return states, set, ok
+		}
+	}
+
+	if len(d.tiles) > 0 {
+		return d.applyTile(payload)
+	}
+
+	return nil, false, false
+}
+
+func (d *Device) applyDevice(payload protocol.Payload) ([]protocol.Payload, bool, bool) {
+	switch p := payload.(type) {
+	case *protocol.DeviceGetService:
+		return one(&protocol.DeviceStateService{
+			Service: protocol.DeviceServiceUDP,
+			Port:    uint32(d.server.Addr().Port),
+		}), false, true
+	case *protocol.DeviceGetHostInfo:
+		return one(&protocol.DeviceStateHostInfo{Signal: 1e-5}), false, true
+	case *protocol.DeviceGetHostFirmware:
+		return one(&protocol.DeviceStateHostFirmware{Build: 1, VersionMajor: 3, VersionMinor: 70}), false, true
+	case *protocol.DeviceGetWifiInfo:
+		return one(&protocol.DeviceStateWifiInfo{Signal: 1e-5}), false, true
+	case *protocol.DeviceGetWifiFirmware:
+		return one(&protocol.DeviceStateWifiFirmware{Build: 1, VersionMajor: 3, VersionMinor: 70}), false, true
+	case *protocol.DeviceGetPower:
+		return one(&protocol.DeviceStatePower{Level: d.power}), false, true
+	case *protocol.DeviceSetPower:
+		d.power = p.Level
+		return one(&protocol.DeviceStatePower{Level: d.power}), true, true
+	case *protocol.DeviceGetLabel:
+		return one(d.labelState()), false, true
+	case *protocol.DeviceSetLabel:
+		d.label = trimString(p.Label[:])
+		return one(d.labelState()), true, true
+	case *protocol.DeviceGetVersion:
+		return one(&protocol.DeviceStateVersion{Vendor: d.product.Vendor, Product: d.product.Product}), false, true
+	case *protocol.DeviceGetInfo:
+		now := time.Now()
+
+		return one(&protocol.DeviceStateInfo{
+			Time:   uint64(now.UnixNano()),
+			Uptime: uint64(now.Sub(d.started)),
+		}), false, true
+	case *protocol.DeviceGetLocation:
+		return one(d.locationState()), false, true
+	case *protocol.DeviceSetLocation:
+		d.location = trimString(p.Label[:])
+		return one(d.locationState()), true, true
+	case *protocol.DeviceGetGroup:
+		return one(d.groupState()), false, true
+	case *protocol.DeviceSetGroup:
+		d.group = trimString(p.Label[:])
+		return one(d.groupState()), true, true
+	case *protocol.DeviceEchoRequest:
+		return one(&protocol.DeviceEchoResponse{Echoing: p.Echoing}), false, true
+	}
+
+	return nil, false, false
+}
+
+func (d *Device) applyLight(payload protocol.Payload) ([]protocol.Payload, bool, bool) {
+	switch p := payload.(type) {
+	case *protocol.LightGet:
+		return one(d.lightState()), false, true
+	case *protocol.LightSetColor:
+		d.color = d.filterColor(p.Color)
+		return one(d.lightState()), true, true
+	case *protocol.LightSetWaveform:
+		// Transient waveforms return to the original color
+		if !p.Transient {
+			d.color = d.filterColor(p.Color)
+		}
+		return one(d.lightState()), true, true
+	case *protocol.LightSetWaveformOptional:
+		if !p.Transient {
+			color := d.color
+
+			if p.SetHue {
+				color.Hue = p.Color.Hue
+			}
+			if p.SetSaturation {
+				color.Saturation = p.Color.Saturation
+			}
+			if p.SetBrightness {
+				color.Brightness = p.Color.Brightness
+			}
+			if p.SetKelvin {
+				color.Kelvin = p.Color.Kelvin
+			}
+
+			d.color = d.filterColor(color)
+		}
+		return one(d.lightState()), true, true
+	case *protocol.LightGetPower:
+		return one(&protocol.LightStatePower{Level: d.power}), false, true
+	case *protocol.LightSetPower:
+		d.power = p.Level
+		return one(&protocol.LightStatePower{Level: d.power}), true, true
+	}
+
+	return nil, false, false
+}
+
+func (d *Device) applyMultiZone(payload protocol.Payload) ([]protocol.Payload, bool, bool) {
+	switch p := payload.(type) {
+	case *protocol.MultiZoneGetColorZones:
+		return d.zoneStates(int(p.StartIndex), int(p.EndIndex)), false, true
+	case *protocol.MultiZoneSetColorZones:
+		for i := int(p.StartIndex); i <= int(p.EndIndex) && i < len(d.zones); i++ {
+			d.zones[i] = d.filterColor(p.Color)
+		}
+		return d.zoneStates(int(p.StartIndex), int(p.EndIndex)), true, true
+	case *protocol.MultiZoneExtendedGetColorZones:
+		return one(d.extendedZoneState()), false, true
+	case *protocol.MultiZoneExtendedSetColorZones:
+		for i := 0; i < int(p.ColorsCount) && i < len(p.Colors); i++ {
+			if zone := int(p.Index) + i; zone < len(d.zones) {
+				d.zones[zone] = d.filterColor(p.Colors[i])
+			}
+		}
+		return one(d.extendedZoneState()), true, true
+	}
+
+	return nil, false, false
+}
+
+func (d *Device) applyTile(payload protocol.Payload) ([]protocol.Payload, bool, bool) {
+	switch p := payload.(type) {
+	case *protocol.TileGetDeviceChain:
+		state := &protocol.TileStateDeviceChain{TileDevicesCount: uint8(len(d.tiles))}
+
+		for i := range d.tiles {
+			if i == len(state.TileDevices) {
+				break
+			}
+
+			state.TileDevices[i] = protocol.TileStateDevice{
+				Width:                8,
+				Height:               8,
+				DeviceVersionVendor:  d.product.Vendor,
+				DeviceVersionProduct: d.product.Product,
+				FirmwareBuild:        1,
+				FirmwareVersionMajor: 3,
+				FirmwareVersionMinor: 70,
+			}
+		}
+		return one(state), false, true
+	case *protocol.TileGet64:
+		return d.tileStates(int(p.TileIndex), int(p.Length), p.Rect), false, true
+	case *protocol.TileSet64:
+		for i := int(p.TileIndex); i < int(p.TileIndex)+int(p.Length) && i < len(d.tiles); i++ {
+			for j, color := range p.Colors {
+				d.tiles[i][j] = d.filterColor(color)
+			}
+		}
+		return d.tileStates(int(p.TileIndex), int(p.Length), p.Rect), true, true
+	}
+
+	return nil, false, false
+}
+
+func (d *Device) applyRelay(payload protocol.Payload) ([]protocol.Payload, bool, bool) {
+	switch p := payload.(type) {
+	case *protocol.RelayGetRPower:
+		if int(p.RelayIndex) >= len(d.relays) {
+			return nil, false, false
+		}
+		return one(&protocol.RelayStateRPower{RelayIndex: p.RelayIndex, Level: d.relays[p.RelayIndex]}), false, true
+	case *protocol.RelaySetRPower:
+		if int(p.RelayIndex) >= len(d.relays) {
+			return nil, false, false
+		}
+		d.relays[p.RelayIndex] = p.Level
+		return one(&protocol.RelayStateRPower{RelayIndex: p.RelayIndex, Level: p.Level}), true, true
+	}
+
+	return nil, false, false
+}
+
+// zoneStates answers GetColorZones like a strip does, with StateZone for a
+// single zone and StateMultiZone for every eight zones otherwise
+func (d *Device) zoneStates(start, end int) []protocol.Payload {
+	count := len(d.zones)
+
+	if end >= count {
+		end = count - 1
+	}
+
+	if start > end {
+		return nil
+	}
+
+	if start == end {
+		return one(&protocol.MultiZoneStateZone{
+			Count: uint8(count),
+			Index: uint8(start),
+			Color: d.zones[start],
+		})
+	}
+
+	states := []protocol.Payload{}
+
+	for index := start - start%8; index <= end; index += 8 {
+		state := &protocol.MultiZoneStateMultiZone{Count: uint8(count), Index: uint8(index)}
+
+		for i := range state.Colors {
+			if index+i < count {
+				state.Colors[i] = d.zones[index+i]
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	return states
+}
+
+func (d *Device) extendedZoneState() *protocol.MultiZoneExtendedStateMultiZone {
+	state := &protocol.MultiZoneExtendedStateMultiZone{Count: uint16(len(d.zones))}
+	state.ColorsCount = uint8(copy(state.Colors[:], d.zones))
+	return state
+}
+
+func (d *Device) tileStates(index, length int, rect protocol.TileBufferRect) []protocol.Payload {
+	states := []protocol.Payload{}
+
+	for i := index; i < index+length && i < len(d.tiles); i++ {
+		states = append(states, &protocol.TileState64{
+			TileIndex: uint8(i),
+			Rect:      rect,
+			Colors:    d.tiles[i],
+		})
+	}
+
+	return states
+}
+
+func (d *Device) lightState() *protocol.LightState {
+	state := &protocol.LightState{Color: d.color, Power: d.power}
+	copy(state.Label[:], d.label)
+	return state
+}
+
+func (d *Device) labelState() *protocol.DeviceStateLabel {
+	state := &protocol.DeviceStateLabel{}
+	copy(state.Label[:], d.label)
+	return state
+}
+
+func (d *Device) locationState() *protocol.DeviceStateLocation {
+	state := &protocol.DeviceStateLocation{Location: labelID(d.location)}
+	copy(state.Label[:], d.location)
+	return state
+}
+
+func (d *Device) groupState() *protocol.DeviceStateGroup {
+	state := &protocol.DeviceStateGroup{Group: labelID(d.group)}
+	copy(state.Label[:], d.group)
+	return state
+}
+
+// filterColor drops hue and saturation on white only devices
+func (d *Device) filterColor(color protocol.LightHsbk) protocol.LightHsbk {
+	if !d.product.Color {
+		color.Hue = 0
+		color.Saturation = 0
+	}
+	return color
+}
+
+// labelID derives the group or location identifier from its label, so
+// devices with the same group label are in the same group
+func labelID(label string) [16]byte {
+	return md5.Sum([]byte(label))
+}
+
+func one(payload protocol.Payload) []protocol.Payload {
+	return []protocol.Payload{payload}
+}
+
+func powerLevel(on bool) uint16 {
+	if on {
+		return 0xFFFF
+	}
+	return 0
+}
+
+func trimString(data []byte) string {
+	return string(bytes.Trim(data, "\x00"))
+}
@@ -0,0 +1,21 @@
+// Package lifxtest provides virtual LIFX devices for testing code built on
+// golifx without real bulbs.
+//
+// A Server hosts any number of devices on one loopback UDP socket and
+// answers like real devices do, so discovery and every Bulb method work
+// end-to-end:
+//
+//	server, _ := lifxtest.NewServer()
+//	defer server.Close()
+//
+//	device := server.AddDevice(lifxtest.DeviceOptions{Label: "Kitchen"})
+//	client := server.Client()
+//	defer client.Close()
+//
+//	bulbs, _ := client.LookupBulbs()
+//	bulbs[0].SetPowerState(true)
+//	fmt.Println(device.Power()) // true
+//
+// Devices can drop and delay packets and answer chosen message types with
+// StateUnhandled to exercise error paths.
+package lifxtest
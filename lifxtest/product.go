@@ -0,0 +1,34 @@
+package lifxtest
+
+// Product describes the hardware a virtual device emulates
+type Product struct {
+	Name    string
+	Vendor  uint32
+	Product uint32
+	// Color devices support hue and saturation, others only white
+	Color bool
+	// Zones is the number of zones of a multizone strip
+	Zones int
+	// Tiles is the number of 8x8 tiles of a tile chain
+	Tiles int
+	// Relays is the number of relays of a switch, switches have no light
+	Relays int
+}
+
+var (
+	// ProductColor is a color bulb
+	ProductColor = Product{Name: "LIFX Color", Vendor: 1, Product: 91, Color: true}
+	// ProductWhite is a white only bulb
+	ProductWhite = Product{Name: "LIFX Mini White", Vendor: 1, Product: 50}
+	// ProductMultizone is a strip with 16 zones
+	ProductMultizone = Product{Name: "LIFX Z", Vendor: 1, Product: 32, Color: true, Zones: 16}
+	// ProductTile is a chain of 5 tiles
+	ProductTile = Product{Name: "LIFX Tile", Vendor: 1, Product: 55, Color: true, Tiles: 5}
+	// ProductSwitch is a switch with 4 relays
+	ProductSwitch = Product{Name: "LIFX Switch", Vendor: 1, Product: 70, Relays: 4}
+)
+
+// HasLight reports whether the product answers light messages
+func (p Product) HasLight() bool {
+	return p.Relays == 0
+}
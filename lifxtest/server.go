@@ -0,0 +1,317 @@
+package lifxtest
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/2tvenom/golifx"
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// Server hosts virtual devices on a single UDP socket. Broadcasts and
+// untargeted packets reach every device, other packets only the device
+// with the target MAC address.
+type Server struct {
+	conn *net.UDPConn
+
+	mu            sync.Mutex
+	devices       []*Device
+	nextMAC       uint64
+	notifyTo      *net.UDPAddr
+	notifyClients bool
+	clients       []*net.UDPAddr
+	random        *rand.Rand
+	watch         func(*Device, uint16)
+
+	done chan struct{}
+}
+
+const (
+	// _FIRST_MAC is d0:73:d5:00:00:01, the LIFX vendor prefix, in golifx byte order
+	_FIRST_MAC = 0x010000d573d0
+	// _MAX_CLIENTS is the number of client addresses remembered for
+	// NotifyClients, the oldest one is forgotten first
+	_MAX_CLIENTS = 32
+)
+
+// NewServer returns a server listening on a random loopback port
+func NewServer() (*Server, error) {
+	return Listen(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+}
+
+// Listen returns a server listening on addr, use port 56700 on a real
+// interface to be found by the LIFX app
+func Listen(addr *net.UDPAddr) (*Server, error) {
+	conn, err := net.ListenUDP("udp4", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Serve(conn), nil
+}
+
+// Serve returns a server answering on a socket set up by the caller, Close
+// closes it
+func Serve(conn *net.UDPConn) *Server {
+	s := &Server{
+		conn:    conn,
+		nextMAC: _FIRST_MAC,
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		done:    make(chan struct{}),
+	}
+
+	go s.serve()
+
+	return s
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() *net.UDPAddr {
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// AddDevice adds a virtual device to the server
+func (s *Server) AddDevice(options DeviceOptions) *Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if options.MAC == 0 {
+		for s.inUse(s.nextMAC) {
+			s.nextMAC += 1 << 40
+		}
+
+		options.MAC = s.nextMAC
+		// The last byte of the MAC address is the sixth one
+		s.nextMAC += 1 << 40
+	}
+
+	d := newDevice(s, options)
+	s.devices = append(s.devices, d)
+	return d
+}
+
+// inUse tells whether a device has the MAC address, s.mu is held
+func (s *Server) inUse(mac uint64) bool {
+	for _, d := range s.devices {
+		if d.mac == mac {
+			return true
+		}
+	}
+	return false
+}
+
+// Devices returns the devices of the server
+func (s *Server) Devices() []*Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Device(nil), s.devices...)
+}
+
+// Client returns a golifx client discovering the devices of the server,
+// options are applied after the ones pointing it at the server. The server
+// holds the bulb port, so Subscribe on the client relies on NotifyClients.
+func (s *Server) Client(options ...golifx.ClientOption) *golifx.Client {
+	addr := s.Addr()
+
+	return golifx.NewClient(append([]golifx.ClientOption{
+		golifx.WithBroadcastAddress(addr.IP),
+		golifx.WithPort(addr.Port),
+		golifx.WithPortListener(false),
+	}, options...)...)
+}
+
+// Notify makes devices send their new state to addr whenever it changes,
+// like bulbs do when they are changed by the LIFX app. nil turns it off.
+func (s *Server) Notify(addr *net.UDPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifyTo = addr
+}
+
+// NotifyClients makes devices send their new state to every client that
+// sent the server a message, like Notify does for a single address. A golifx
+// Subscribe on a client of the server receives the changes this way.
+func (s *Server) NotifyClients(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifyClients = enabled
+}
+
+// OnMessage sets a function called with the device and the message type
+// after every message a device handled
+func (s *Server) OnMessage(watch func(d *Device, messageType uint16)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watch = watch
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	<-s.done
+	return err
+}
+
+func (s *Server) serve() {
+	defer close(s.done)
+
+	buff := make([]byte, 1500)
+
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buff)
+
+		if err != nil {
+			if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
+				continue
+			}
+			return
+		}
+
+		in := &protocol.Packet{}
+
+		if err := in.UnmarshalBinary(buff[:n]); err != nil {
+			continue
+		}
+
+		s.remember(addr)
+
+		for _, d := range s.Devices() {
+			if in.Tagged || in.Target == 0 || in.Target == d.mac {
+				s.handle(d, in, addr)
+			}
+		}
+	}
+}
+
+// handle passes the packet to the device and sends the replies, applying
+// the loss and latency of the device
+func (s *Server) handle(d *Device, in *protocol.Packet, addr *net.UDPAddr) {
+	loss, latency := d.faults()
+
+	if loss > 0 && s.chance() < loss {
+		return
+	}
+
+	changed := d.state()
+	replies := d.handle(in)
+
+	if d.state() != changed {
+		s.notifyState(d)
+	}
+
+	s.mu.Lock()
+	watch := s.watch
+	s.mu.Unlock()
+
+	if watch != nil {
+		watch(d, in.Type)
+	}
+
+	packets := [][]byte{}
+
+	for _, reply := range replies {
+		out := protocol.NewPacket(reply)
+		out.Source = in.Source
+		out.Target = d.mac
+		out.Sequence = in.Sequence
+
+		data, err := out.MarshalBinary()
+
+		if err != nil {
+			continue
+		}
+		packets = append(packets, data)
+	}
+
+	send := func() {
+		for _, data := range packets {
+			s.conn.WriteToUDP(data, addr)
+		}
+	}
+
+	if latency > 0 {
+		time.AfterFunc(latency, send)
+		return
+	}
+
+	send()
+}
+
+// remember adds addr to the clients notified with NotifyClients
+func (s *Server) remember(addr *net.UDPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, client := range s.clients {
+		if client.IP.Equal(addr.IP) && client.Port == addr.Port {
+			return
+		}
+	}
+
+	if len(s.clients) == _MAX_CLIENTS {
+		s.clients = s.clients[1:]
+	}
+
+	s.clients = append(s.clients, addr)
+}
+
+func (s *Server) chance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.random.Float64()
+}
+
+// notify sends the state of the device to the notify address and, with
+// NotifyClients, to the clients
+func (s *Server) notify(d *Device, state protocol.Payload) {
+	s.mu.Lock()
+	addrs := []*net.UDPAddr{}
+
+	if s.notifyTo != nil {
+		addrs = append(addrs, s.notifyTo)
+	}
+
+	if s.notifyClients {
+		addrs = append(addrs, s.clients...)
+	}
+	s.mu.Unlock()
+
+	if len(addrs) == 0 {
+		return
+	}
+
+	out := protocol.NewPacket(state)
+	out.Target = d.mac
+
+	data, err := out.MarshalBinary()
+
+	if err != nil {
+		return
+	}
+
+	for _, addr := range addrs {
+		s.conn.WriteToUDP(data, addr)
+	}
+}
+
+// notifyState sends the full light state of the device to the notify
+// addresses after a client changed it
+func (s *Server) notifyState(d *Device) {
+	d.mu.Lock()
+	var state protocol.Payload = &protocol.DeviceStatePower{Level: d.power}
+
+	if d.product.HasLight() {
+		state = d.lightState()
+	}
+	d.mu.Unlock()
+
+	s.notify(d, state)
+}
@@ -0,0 +1,777 @@
+package lifxtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/2tvenom/golifx"
+	"github.com/2tvenom/golifx/lifxtest"
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// setup starts a server with a device per options and returns the bulbs
+// found by a client of the server
+func setup(t *testing.T, options ...lifxtest.DeviceOptions) (*lifxtest.Server, *golifx.Client, []*golifx.Bulb) {
+	t.Helper()
+
+	server, err := lifxtest.NewServer()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { server.Close() })
+
+	for _, o := range options {
+		server.AddDevice(o)
+	}
+
+	client := server.Client(golifx.WithDeadline(100 * time.Millisecond))
+	t.Cleanup(func() { client.Close() })
+
+	bulbs, err := client.LookupBulbs()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bulbs) != len(options) {
+		t.Fatalf("found %d bulbs, want %d", len(bulbs), len(options))
+	}
+
+	return server, client, bulbs
+}
+
+// bulbFor returns the bulb of the device
+func bulbFor(t *testing.T, bulbs []*golifx.Bulb, d *lifxtest.Device) *golifx.Bulb {
+	t.Helper()
+
+	for _, b := range bulbs {
+		if b.HardwareAddress() == d.MAC() {
+			return b
+		}
+	}
+
+	t.Fatalf("device %x not found", d.MAC())
+	return nil
+}
+
+func TestDiscovery(t *testing.T) {
+	server, client, bulbs := setup(t,
+		lifxtest.DeviceOptions{Label: "Kitchen"},
+		lifxtest.DeviceOptions{Label: "Hall", Product: lifxtest.ProductMultizone},
+		lifxtest.DeviceOptions{Label: "Porch", Product: lifxtest.ProductSwitch},
+	)
+
+	for _, d := range server.Devices() {
+		b := bulbFor(t, bulbs, d)
+
+		if b.IP() == nil {
+			t.Errorf("%s has no address", b.MacAddress())
+		}
+
+		label, err := b.GetLabel()
+
+		if err != nil || label != d.Label() {
+			t.Errorf("got label %q, %v, want %q", label, err, d.Label())
+		}
+	}
+
+	if known := client.Bulbs(); len(known) != 3 {
+		t.Fatalf("client knows %d bulbs, want 3", len(known))
+	}
+}
+
+func TestDiscoverEvents(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{}, lifxtest.DeviceOptions{})
+	d, other := server.Devices()[0], server.Devices()[1]
+
+	// Probes are sent faster than the default broadcast rate limit allows
+	client := server.Client(golifx.WithBroadcastRateLimit(golifx.RateLimit{}))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := client.Discover(ctx, golifx.DiscoverOptions{
+		Interval:  20 * time.Millisecond,
+		LostAfter: 60 * time.Millisecond,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func(want golifx.DiscoveryEventType) *golifx.Bulb {
+		t.Helper()
+
+		event, ok := <-events
+
+		if !ok || event.Type != want {
+			t.Fatalf("got %+v, %t, want %s", event, ok, want)
+		}
+
+		return event.Bulb
+	}
+
+	expect := func(want golifx.DiscoveryEventType) {
+		t.Helper()
+
+		if bulb := next(want); bulb.HardwareAddress() != d.MAC() {
+			t.Fatalf("got %s of %s, want %x", want, bulb.MacAddress(), d.MAC())
+		}
+	}
+
+	if a, b := next(golifx.BulbFound), next(golifx.BulbFound); a == b {
+		t.Fatalf("%s found twice", a.MacAddress())
+	}
+
+	d.SetLoss(1)
+	expect(golifx.BulbLost)
+
+	d.SetLoss(0)
+	expect(golifx.BulbFound)
+
+	// Probing goes on while nobody reads the events
+	d.SetLoss(1)
+	probes := other.Received(protocol.TypeDeviceGetService)
+	time.Sleep(200 * time.Millisecond)
+
+	if n := other.Received(protocol.TypeDeviceGetService) - probes; n < 5 {
+		t.Errorf("%d probes sent while the events were not read, want about 10", n)
+	}
+
+	d.SetLoss(0)
+	expect(golifx.BulbLost)
+	expect(golifx.BulbFound)
+
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestPower(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{})
+	d := server.Devices()[0]
+
+	if err := bulbs[0].SetPowerState(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if !d.Power() {
+		t.Fatal("device is off after SetPowerState(true)")
+	}
+
+	d.SetPower(false)
+
+	if on, err := bulbs[0].GetPowerState(); err != nil || on {
+		t.Fatalf("got %t, %v, want off", on, err)
+	}
+
+	if err := bulbs[0].SetPowerDurationState(true, 100); err != nil || !d.Power() {
+		t.Fatalf("got %t, %v, want on", d.Power(), err)
+	}
+}
+
+func TestColor(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{Label: "Kitchen", Power: true})
+	d := server.Devices()[0]
+
+	color := &golifx.HSBK{Hue: 21845, Saturation: 65535, Brightness: 32768, Kelvin: 3500}
+
+	if err := bulbs[0].SetColorState(color, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := d.Color(); got.Hue != color.Hue || got.Brightness != color.Brightness {
+		t.Fatalf("device color %+v, want %+v", got, color)
+	}
+
+	d.SetColor(protocol.LightHsbk{Hue: 100, Saturation: 200, Brightness: 300, Kelvin: 2700})
+
+	state, err := bulbs[0].GetColorState()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *state.Color != (golifx.HSBK{Hue: 100, Saturation: 200, Brightness: 300, Kelvin: 2700}) || !state.Power || state.Label != "Kitchen" {
+		t.Fatalf("got %+v %+v", state, state.Color)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{Label: "Kitchen"})
+
+	if err := bulbs[0].SetLabel("Hall"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := server.Devices()[0].Label(); got != "Hall" {
+		t.Fatalf("device label %q, want Hall", got)
+	}
+
+	if label, err := bulbs[0].GetLabel(); err != nil || label != "Hall" {
+		t.Fatalf("got %q, %v, want Hall", label, err)
+	}
+}
+
+func TestDeviceInfo(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{Group: "Upstairs", Location: "Home"})
+	bulb := bulbs[0]
+
+	if version, err := bulb.GetVersion(); err != nil || version.VendorId != 1 || version.ProductId != 91 {
+		t.Fatalf("got %+v, %v, want LIFX Color", version, err)
+	}
+
+	firmware := golifx.BulbFirmware{Build: 1, Version: 3<<16 | 70}
+
+	if got, err := bulb.GetHostFirmware(); err != nil || *got != firmware {
+		t.Fatalf("host firmware %+v, %v, want %+v", got, err, firmware)
+	}
+
+	if got, err := bulb.GetWifiFirmware(); err != nil || *got != firmware {
+		t.Fatalf("wifi firmware %+v, %v, want %+v", got, err, firmware)
+	}
+
+	if got, err := bulb.GetStateHostInfo(); err != nil || got.Signal != 1e-5 {
+		t.Fatalf("host info %+v, %v", got, err)
+	}
+
+	if got, err := bulb.GetWifiInfo(); err != nil || got.Signal != 1e-5 {
+		t.Fatalf("wifi info %+v, %v", got, err)
+	}
+
+	if info, err := bulb.GetInfo(); err != nil || info.Time <= 0 || info.UpTime <= 0 {
+		t.Fatalf("info %+v, %v", info, err)
+	}
+
+	location, err := bulb.GetLocation()
+
+	if err != nil || location.Label != "Home" || len(location.Location) != 16 {
+		t.Fatalf("location %+v, %v", location, err)
+	}
+
+	group, err := bulb.GetGroup()
+
+	if err != nil || group.Label != "Upstairs" || string(group.Location) == string(location.Location) {
+		t.Fatalf("group %+v, %v", group, err)
+	}
+
+	echo, err := bulb.EchoRequest([]byte("ping"))
+
+	if err != nil || len(echo) != 64 || string(echo[:4]) != "ping" {
+		t.Fatalf("echo %q, %v", echo, err)
+	}
+
+	if _, err = bulb.EchoRequest(make([]byte, 65)); err != golifx.ErrEchoMaxRequest {
+		t.Fatalf("got %v, want ErrEchoMaxRequest", err)
+	}
+
+	if received := server.Devices()[0].Received(protocol.TypeDeviceEchoRequest); received != 1 {
+		t.Fatalf("device got %d echo requests, want 1", received)
+	}
+}
+
+func TestPowerDuration(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{Power: true})
+	d := server.Devices()[0]
+
+	if err := bulbs[0].SetPowerDurationState(false, 100); err != nil || d.Power() {
+		t.Fatalf("got %t, %v, want off", d.Power(), err)
+	}
+
+	if on, err := bulbs[0].GetPowerDurationState(); err != nil || on {
+		t.Fatalf("got %t, %v, want off", on, err)
+	}
+
+	d.SetPower(true)
+
+	if on, err := bulbs[0].GetPowerDurationState(); err != nil || !on {
+		t.Fatalf("got %t, %v, want on", on, err)
+	}
+
+	if d.Received(protocol.TypeLightSetPower) != 1 || d.Received(protocol.TypeLightGetPower) != 2 {
+		t.Fatal("light power messages were not used")
+	}
+}
+
+func TestScan(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{}, lifxtest.DeviceOptions{})
+
+	client := server.Client(golifx.WithDeadline(100 * time.Millisecond))
+	defer client.Close()
+
+	network := &net.IPNet{IP: server.Addr().IP, Mask: net.CIDRMask(32, 32)}
+
+	bulbs, err := client.Scan(context.Background(), []*net.IPNet{network}, golifx.ScanOptions{Wait: 50 * time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bulbs) != 2 || len(client.Bulbs()) != 2 {
+		t.Fatalf("scan found %d bulbs, client has %d, want 2", len(bulbs), len(client.Bulbs()))
+	}
+
+	for _, d := range server.Devices() {
+		if bulb := bulbFor(t, bulbs, d); bulb.IP().String() != server.Addr().String() {
+			t.Fatalf("bulb %s at %s", bulb, bulb.IP())
+		}
+	}
+
+	if _, err = client.Scan(context.Background(), []*net.IPNet{{IP: net.IPv6loopback, Mask: net.CIDRMask(128, 128)}}, golifx.ScanOptions{}); err != golifx.ErrNotIPv4Network {
+		t.Fatalf("got %v, want ErrNotIPv4Network", err)
+	}
+}
+
+func TestWaveform(t *testing.T) {
+	original := protocol.LightHsbk{Hue: 1, Saturation: 2, Brightness: 3, Kelvin: 3500}
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{Color: original})
+	d := server.Devices()[0]
+
+	color := &golifx.HSBK{Hue: 40000, Saturation: 65535, Brightness: 65535, Kelvin: 3500}
+
+	if _, err := bulbs[0].SetWaveform(true, color, 1000, 2, 0, golifx.WAVEFORM_PULSE); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Received(protocol.TypeLightSetWaveform) != 1 || d.Color() != original {
+		t.Fatalf("transient waveform changed the color to %+v", d.Color())
+	}
+
+	if _, err := bulbs[0].SetWaveform(false, color, 1000, 1, 0, golifx.WAVEFORM_SINE); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Color().Hue != color.Hue {
+		t.Fatalf("got color %+v, want hue %d", d.Color(), color.Hue)
+	}
+}
+
+func TestColorZones(t *testing.T) {
+	zones := make([]protocol.LightHsbk, 16)
+
+	for i := range zones {
+		zones[i] = protocol.LightHsbk{Hue: uint16(i), Kelvin: 3500}
+	}
+
+	_, _, bulbs := setup(t, lifxtest.DeviceOptions{Product: lifxtest.ProductMultizone, Zones: zones})
+
+	colors, err := bulbs[0].GetColorZones(0, 255)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(colors) != 16 {
+		t.Fatalf("got %d zones, want 16", len(colors))
+	}
+
+	for i, color := range colors {
+		if color.Hue != uint16(i) {
+			t.Fatalf("zone %d has hue %d", i, color.Hue)
+		}
+	}
+
+	if colors, err := bulbs[0].GetColorZones(5, 5); err != nil || len(colors) != 1 || colors[0].Hue != 5 {
+		t.Fatalf("got %v, %v, want zone 5", colors, err)
+	}
+
+	if _, err := bulbs[0].GetColorZones(5, 4); err != golifx.ErrInvalidZoneRange {
+		t.Fatalf("got %v, want ErrInvalidZoneRange", err)
+	}
+}
+
+// eventually fails the test unless done returns true within a second
+func eventually(t *testing.T, done func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); !done(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{Power: true})
+	d := server.Devices()[0]
+
+	// A frame every 100ms gives the test time to replace a waiting frame
+	client := server.Client(
+		golifx.WithDeadline(100*time.Millisecond),
+		golifx.WithRateLimit(golifx.RateLimit{Rate: 10}),
+	)
+	defer client.Close()
+
+	reports := make(chan golifx.StreamStats, 100)
+
+	stream := client.NewBulb(server.Addr().IP, d.MAC()).Stream(context.Background(), golifx.StreamOptions{
+		SampleEvery:    2,
+		ReportInterval: 20 * time.Millisecond,
+		Report:         func(stats golifx.StreamStats) { reports <- stats },
+	})
+	defer stream.Close()
+
+	frame := func(hue uint16) {
+		stream.SetColor(golifx.HSBK{Hue: hue, Brightness: 65535, Kelvin: 3500}, 0)
+	}
+
+	frame(1000)
+	eventually(t, func() bool { return stream.Stats().Sent == 1 })
+
+	// The second frame waits for the rate limit and is replaced
+	frame(2000)
+	frame(3000)
+	eventually(t, func() bool { return stream.Stats().Sent == 2 && stream.Stats().Sampled == 1 })
+
+	if stats := stream.Stats(); stats.Dropped != 1 || stats.Acked != 1 || stats.Loss() != 0 {
+		t.Fatalf("got %+v", stats)
+	}
+
+	// Frames without an acknowledgement may still be on their way
+	eventually(t, func() bool { return d.Received(protocol.TypeLightSetColor) == 2 })
+
+	if d.Color().Hue != 3000 {
+		t.Fatalf("device color %+v, want the last frame", d.Color())
+	}
+
+	// The third frame is sampled, its acknowledgement is lost
+	d.SetLoss(1)
+	frame(4000)
+	eventually(t, func() bool { return stream.Stats().Sampled == 2 })
+
+	if stats := stream.Stats(); stats.Sent != 3 || stats.Acked != 1 || stats.Loss() != 0.5 {
+		t.Fatalf("got %+v, want half of the samples lost", stats)
+	}
+
+	select {
+	case <-reports:
+	default:
+		t.Fatal("no statistics reported")
+	}
+}
+
+func TestContext(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{})
+	d := server.Devices()[0]
+
+	// A client deadline far beyond the context ones
+	client := server.Client(golifx.WithDeadline(time.Second))
+	defer client.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.LookupBulbsContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("lookup got %v, want context.Canceled", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	bulbs, err := client.LookupBulbsContext(ctx)
+
+	if err != nil || len(bulbs) != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("lookup got %d bulbs, %v after %s", len(bulbs), err, time.Since(start))
+	}
+
+	if err = bulbs[0].SetPowerStateContext(cancelled, true); !errors.Is(err, context.Canceled) {
+		t.Fatalf("set got %v, want context.Canceled", err)
+	}
+
+	d.SetLatency(time.Second)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+
+	if _, err = bulbs[0].GetPowerStateContext(ctx); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("get got %v after %s, want context.DeadlineExceeded", err, time.Since(start))
+	}
+}
+
+func TestUnsupportedMessage(t *testing.T) {
+	_, _, bulbs := setup(t, lifxtest.DeviceOptions{Unhandled: []uint16{protocol.TypeLightGet}})
+
+	_, err := bulbs[0].GetColorState()
+
+	var unsupported *golifx.UnsupportedMessageError
+	var e *golifx.Error
+
+	if !errors.As(err, &unsupported) || unsupported.MessageType != protocol.TypeLightGet {
+		t.Fatalf("got %v, want UnsupportedMessageError for LightGet", err)
+	}
+
+	if !errors.As(err, &e) || e.MAC != bulbs[0].MacAddress() || e.ReceivedType != protocol.TypeDeviceStateUnhandled {
+		t.Fatalf("got %v, want an *Error of the bulb", err)
+	}
+
+	// Other messages are still answered
+	if _, err := bulbs[0].GetPowerState(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server, _, bulbs := setup(t, lifxtest.DeviceOptions{})
+	server.Devices()[0].SetLoss(1)
+
+	ctx := golifx.ContextWithRetryPolicy(context.Background(), golifx.RetryPolicy{
+		MaxAttempts: 2,
+		Timeout:     20 * time.Millisecond,
+	})
+
+	_, err := bulbs[0].GetPowerStateContext(ctx)
+
+	var e *golifx.Error
+
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want *golifx.Error", err)
+	}
+
+	if !e.IsTimeout() || !errors.Is(err, golifx.ErrTimeout) {
+		t.Fatalf("%v is not a timeout", err)
+	}
+
+	// Two unicast attempts and the broadcast fallback
+	if e.Attempts != 3 || e.RequestType != protocol.TypeDeviceGetPower || e.MAC != bulbs[0].MacAddress() {
+		t.Fatalf("got %+v", e)
+	}
+
+	// Only a missing acknowledgement is ErrNoResponse
+	if errors.Is(err, golifx.ErrNoResponse) {
+		t.Fatalf("%v matches ErrNoResponse", err)
+	}
+
+	err = bulbs[0].SetPowerStateContext(ctx, true)
+
+	if !errors.Is(err, golifx.ErrNoResponse) || !errors.Is(err, golifx.ErrTimeout) {
+		t.Fatalf("got %v, want ErrNoResponse", err)
+	}
+}
+
+func TestTimeoutDefaultPolicy(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{})
+	d := server.Devices()[0]
+	d.SetLoss(1)
+
+	client := server.Client()
+	defer client.Close()
+
+	start := time.Now()
+	_, err := client.NewBulb(server.Addr().IP, d.MAC()).GetPowerState()
+	elapsed := time.Since(start)
+
+	var e *golifx.Error
+
+	if !errors.As(err, &e) || !e.IsTimeout() {
+		t.Fatalf("got %v, want a timeout", err)
+	}
+
+	// Three unicast attempts and the broadcast fit the default deadline
+	if e.Attempts != 4 {
+		t.Errorf("got %d attempts, want 4", e.Attempts)
+	}
+
+	if elapsed > 600*time.Millisecond {
+		t.Errorf("request took %s, want about the 500ms deadline", elapsed)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{})
+	d := server.Devices()[0]
+	client := server.Client()
+	defer client.Close()
+
+	ip := server.Addr().IP
+
+	if err := client.NewBulb(ip, d.MAC()).Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := client.NewBulb(ip, d.MAC()+1)
+	err := other.Verify()
+
+	var e *golifx.Error
+
+	if !errors.Is(err, golifx.ErrHardwareAddressMismatch) || !errors.As(err, &e) || e.MAC != other.MacAddress() {
+		t.Fatalf("got %v, want ErrHardwareAddressMismatch", err)
+	}
+
+	// A cancelled verification is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = client.NewBulb(ip, d.MAC()).VerifyContext(ctx)
+
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &e) || e.IsTimeout() {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	// Lost packets are retried
+	d.SetLoss(1)
+
+	ctx = golifx.ContextWithRetryPolicy(context.Background(), golifx.RetryPolicy{
+		MaxAttempts: 2,
+		Timeout:     20 * time.Millisecond,
+	})
+
+	if err := client.NewBulb(ip, d.MAC()).VerifyContext(ctx); !errors.As(err, &e) || !e.IsTimeout() || e.Attempts != 2 {
+		t.Fatalf("got %v, want a timeout after 2 attempts", err)
+	}
+}
+
+func TestNotifyClients(t *testing.T) {
+	server, client, bulbs := setup(t, lifxtest.DeviceOptions{Label: "Kitchen"})
+	d := server.Devices()[0]
+	server.NotifyClients(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := client.Subscribe(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the bulb while events update it must not race
+	done := make(chan struct{})
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	go func() {
+		defer close(done)
+
+		for ctx.Err() == nil {
+			_ = bulbs[0].String()
+			json.Marshal(bulbs[0])
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	d.SetPower(true)
+
+	if event := <-events; event.Type != golifx.PowerChanged || !event.Power || event.Bulb != bulbs[0] {
+		t.Fatalf("got %+v, want power on of %s", event, bulbs[0].MacAddress())
+	}
+
+	// A change by another client reaches the subscriber as well
+	other := server.Client()
+	defer other.Close()
+
+	if err := other.NewBulb(server.Addr().IP, d.MAC()).SetLabel("Hall"); err != nil {
+		t.Fatal(err)
+	}
+
+	for event := range events {
+		if event.Type == golifx.LabelChanged {
+			if event.Label != "Hall" {
+				t.Fatalf("got label %q, want Hall", event.Label)
+			}
+			return
+		}
+	}
+
+	t.Fatal("no label change received")
+}
+
+func TestSubscribePortTaken(t *testing.T) {
+	server, _, _ := setup(t, lifxtest.DeviceOptions{})
+	addr := server.Addr()
+
+	// The server holds the port the client listens on for state packets
+	client := golifx.NewClient(golifx.WithBroadcastAddress(addr.IP), golifx.WithPort(addr.Port))
+	defer client.Close()
+
+	if _, err := client.Subscribe(context.Background()); err == nil {
+		t.Fatal("subscribed although the port is taken")
+	}
+}
+
+func TestSubscribeLateReplies(t *testing.T) {
+	server, client, bulbs := setup(t, lifxtest.DeviceOptions{Label: "Kitchen"})
+	d := server.Devices()[0]
+	server.NotifyClients(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := client.Subscribe(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The label arrives after the request gave up
+	d.SetLatency(50 * time.Millisecond)
+
+	_, err = bulbs[0].GetLabelContext(golifx.ContextWithRetryPolicy(ctx, golifx.RetryPolicy{
+		MaxAttempts: 1,
+		Timeout:     10 * time.Millisecond,
+	}))
+
+	if !errors.Is(err, golifx.ErrTimeout) {
+		t.Fatalf("got %v, want a timeout", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	d.SetColor(protocol.LightHsbk{Hue: 100, Kelvin: 3500})
+
+	// The first event comes from the color change, not the late label
+	if event := <-events; event.Type != golifx.ColorChanged {
+		t.Fatalf("got %s event %+v, want color", event.Type, event)
+	}
+}
+
+func TestAddDeviceMAC(t *testing.T) {
+	server, err := lifxtest.NewServer()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer server.Close()
+
+	first := server.AddDevice(lifxtest.DeviceOptions{})
+	server.AddDevice(lifxtest.DeviceOptions{MAC: first.MAC() + 1<<40})
+
+	// The next address is taken by the device added with a MAC address
+	if third := server.AddDevice(lifxtest.DeviceOptions{}); third.MAC() != first.MAC()+2<<40 {
+		t.Fatalf("got %x, want %x", third.MAC(), first.MAC()+2<<40)
+	}
+}
+
+func TestClose(t *testing.T) {
+	_, client, bulbs := setup(t, lifxtest.DeviceOptions{})
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A closed client does not open a new socket
+	if _, err := bulbs[0].GetPowerState(); !errors.Is(err, golifx.ErrConnectionClosed) {
+		t.Fatalf("got %v, want ErrConnectionClosed", err)
+	}
+
+	if _, err := client.LookupBulbs(); err != golifx.ErrConnectionClosed {
+		t.Fatalf("got %v, want ErrConnectionClosed", err)
+	}
+
+	if _, err := client.Subscribe(context.Background()); err != golifx.ErrConnectionClosed {
+		t.Fatalf("got %v, want ErrConnectionClosed", err)
+	}
+}
@@ -0,0 +1,182 @@
+package golifx
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+type (
+	// MemoryNetwork connects MemoryTransports within the process. Packets
+	// are delivered to the transport bound to the destination address or,
+	// failing that, to one bound to any address on the destination port.
+	// Packets sent to 255.255.255.255 reach every other transport on the
+	// destination port. Like UDP, packets are dropped when the receiver
+	// does not keep up.
+	MemoryNetwork struct {
+		mu         sync.Mutex
+		transports map[string]*MemoryTransport
+		nextPort   int
+	}
+
+	// MemoryTransport is a Transport on a MemoryNetwork
+	MemoryTransport struct {
+		network *MemoryNetwork
+		addr    *net.UDPAddr
+		packets chan memoryPacket
+		closed  chan struct{}
+		once    sync.Once
+	}
+
+	memoryPacket struct {
+		data []byte
+		from *net.UDPAddr
+	}
+)
+
+var (
+	// ErrAddressInUse is returned by MemoryNetwork.Listen for an address
+	// another transport is bound to
+	ErrAddressInUse = errors.New("Address already in use")
+)
+
+// NewMemoryNetwork returns an empty in-memory network
+func NewMemoryNetwork() *MemoryNetwork {
+	return &MemoryNetwork{
+		transports: map[string]*MemoryTransport{},
+		nextPort:   50000,
+	}
+}
+
+// Listen returns a transport bound to addr. A nil address means 127.0.0.1
+// and a zero port picks a free one.
+func (n *MemoryNetwork) Listen(addr *net.UDPAddr) (*MemoryTransport, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	bound := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	if addr != nil {
+		bound = &net.UDPAddr{IP: addr.IP, Port: addr.Port}
+	}
+
+	if bound.IP == nil {
+		bound.IP = net.IPv4zero
+	}
+
+	for bound.Port == 0 {
+		n.nextPort++
+
+		if _, used := n.transports[(&net.UDPAddr{IP: bound.IP, Port: n.nextPort}).String()]; !used {
+			bound.Port = n.nextPort
+		}
+	}
+
+	if _, used := n.transports[bound.String()]; used {
+		return nil, ErrAddressInUse
+	}
+
+	t := &MemoryTransport{
+		network: n,
+		addr:    bound,
+		packets: make(chan memoryPacket, _MAX_PENDING_RESPONSES),
+		closed:  make(chan struct{}),
+	}
+
+	n.transports[bound.String()] = t
+	return t, nil
+}
+
+// receivers returns the transports a packet to addr is delivered to
+func (n *MemoryNetwork) receivers(from *MemoryTransport, addr *net.UDPAddr) []*MemoryTransport {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if addr.IP.Equal(net.IPv4bcast) {
+		receivers := []*MemoryTransport{}
+
+		for _, t := range n.transports {
+			if t != from && t.addr.Port == addr.Port {
+				receivers = append(receivers, t)
+			}
+		}
+		return receivers
+	}
+
+	if t, ok := n.transports[addr.String()]; ok {
+		return []*MemoryTransport{t}
+	}
+
+	for _, t := range n.transports {
+		if t.addr.Port == addr.Port && t.addr.IP.IsUnspecified() {
+			return []*MemoryTransport{t}
+		}
+	}
+
+	return nil
+}
+
+func (n *MemoryNetwork) remove(t *MemoryTransport) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.transports[t.addr.String()] == t {
+		delete(n.transports, t.addr.String())
+	}
+}
+
+func (t *MemoryTransport) WriteTo(p []byte, addr net.Addr) (int, error) {
+	select {
+	case <-t.closed:
+		return 0, ErrConnectionClosed
+	default:
+	}
+
+	udpAddr, ok := addr.(*net.UDPAddr)
+
+	if !ok {
+		var err error
+
+		if udpAddr, err = net.ResolveUDPAddr("udp", addr.String()); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, receiver := range t.network.receivers(t, udpAddr) {
+		receiver.deliver(memoryPacket{
+			data: append([]byte(nil), p...),
+			from: t.addr,
+		})
+	}
+
+	return len(p), nil
+}
+
+func (t *MemoryTransport) deliver(packet memoryPacket) {
+	select {
+	case <-t.closed:
+	case t.packets <- packet:
+	default:
+	}
+}
+
+func (t *MemoryTransport) ReadFrom(p []byte) (int, net.Addr, error) {
+	select {
+	case packet := <-t.packets:
+		return copy(p, packet.data), packet.from, nil
+	case <-t.closed:
+		return 0, nil, ErrConnectionClosed
+	}
+}
+
+func (t *MemoryTransport) Close() error {
+	t.once.Do(func() {
+		t.network.remove(t)
+		close(t.closed)
+	})
+	return nil
+}
+
+func (t *MemoryTransport) LocalAddr() net.Addr {
+	return t.addr
+}
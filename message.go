@@ -1,12 +1,16 @@
 package golifx
 
 import (
-	"encoding/binary"
 	"io"
-	"math"
 	"net"
+
+	"github.com/2tvenom/golifx/protocol"
 )
 
+// DecodeError is returned for packets that are too short, carry a wrong size
+// field or protocol number or a payload too short for their message type
+type DecodeError = protocol.DecodeError
+
 type (
 	header struct {
 		//frame
@@ -28,12 +32,15 @@ type (
 		*header
 		payout []byte
 		addr   net.Addr
+		iface  string
+		// link is the key of the link the message was received on
+		link string
 	}
 )
 
 const (
 	_DEFAULT_SOURCE_VALUE  = 7
-	_DEFAULT_HEADER_LENGTH = 36
+	_DEFAULT_HEADER_LENGTH = protocol.HeaderLength
 )
 
 func makeMessage() *message {
@@ -52,50 +59,67 @@ func makeMessageWithType(tp uint16) *message {
 	return msg
 }
 
-func (m *message) Write(data []byte) (n int, err error) {
+func makeMessageWithPayload(payload protocol.Payload) (*message, error) {
+	data, err := payload.MarshalBinary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	msg := makeMessageWithType(payload.Type())
+	msg.payout = data
+	return msg, nil
+}
 
-	m.tagged = (data[3] >> 5 & 1) == 1
-	m.addressable = (data[3] >> 4 & 1) == 1
+// Write decodes a received packet. The size field, protocol number and
+// payload length of known message types are validated, a *DecodeError is
+// returned for invalid packets. The payload is copied, data can be reused.
+func (m *message) Write(data []byte) (n int, err error) {
+	packet := &protocol.Packet{}
 
-	readUint32(data[4:8], &m.source)
-	readUint64(data[8:16], &m.target)
+	if err := packet.UnmarshalBinary(data); err != nil {
+		return 0, err
+	}
 
-	m.ack_required = (data[22] >> 1 & 1) == 1
-	m.res_required = (data[22] & 1) == 1
-	m.sequence = data[23]
+	h := packet.Header
 
-	readUint16(data[32:34], &m._type)
+	m.tagged = h.Tagged
+	m.addressable = h.Addressable
+	m.source = h.Source
+	m.target = h.Target
+	m.ack_required = h.AckRequired
+	m.res_required = h.ResRequired
+	m.sequence = h.Sequence
+	m._type = h.Type
 
 	if len(data) > _DEFAULT_HEADER_LENGTH {
-		m.payout = data[_DEFAULT_HEADER_LENGTH:]
+		m.payout = append([]byte(nil), data[_DEFAULT_HEADER_LENGTH:]...)
 	}
 
 	return len(data), nil
 }
 
 func (m *message) ReadRaw() []byte {
-	buff := make([]byte, 512)
+	buff := make([]byte, _DEFAULT_HEADER_LENGTH+len(m.payout))
 	n, _ := m.Read(buff)
 	return buff[:n]
 }
 
 func (m *message) Read(p []byte) (n int, err error) {
-	length := _DEFAULT_HEADER_LENGTH
-
-	if m.payout != nil {
-		length = _DEFAULT_HEADER_LENGTH + len(m.payout)
+	h := protocol.Header{
+		Size:        uint16(_DEFAULT_HEADER_LENGTH + len(m.payout)),
+		Tagged:      m.tagged,
+		Addressable: m.addressable,
+		Protocol:    protocol.ProtocolNumber,
+		Source:      m.source,
+		Target:      m.target,
+		AckRequired: m.ack_required,
+		ResRequired: m.res_required,
+		Sequence:    m.sequence,
+		Type:        m._type,
 	}
 
-	data := make([]byte, _DEFAULT_HEADER_LENGTH)
-
-	writeUInt16(data[0:2], uint16(length))
-
-	data[3] = (boolToUInt8(m.tagged) << 5) | (boolToUInt8(m.addressable) << 4) | (uint8(1) << 2)
-	writeUInt32(data[4:8], m.source)
-	writeUInt64(data[8:16], m.target)
-	data[22] = (boolToUInt8(m.ack_required))<<1 | boolToUInt8(m.res_required)
-	data[23] = m.sequence
-	writeUInt16(data[32:34], m._type)
+	data, _ := h.MarshalBinary()
 
 	if m.payout != nil {
 		data = append(data, m.payout...)
@@ -103,30 +127,7 @@ func (m *message) Read(p []byte) (n int, err error) {
 
 	copy(p, data)
 
-	return length, io.EOF
-}
-
-func boolToUInt8(b bool) uint8 {
-	if b {
-		return 1
-	}
-	return 0
-}
-
-func writeUInt16(buff []byte, data uint16) {
-	buff[0] = byte(data)
-	buff[1] = byte(data >> 8)
-}
-
-func writeInt16(buff []byte, data int16) {
-	buff[0] = byte(data)
-	buff[1] = byte(data >> 8)
-}
-
-func writeUInt32(buff []byte, data uint32) {
-	for i := 0; i < 4; i++ {
-		buff[i] = byte(data >> uint(i*8))
-	}
+	return len(data), io.EOF
 }
 
 func writeUInt64(buff []byte, data uint64) {
@@ -135,16 +136,6 @@ func writeUInt64(buff []byte, data uint64) {
 	}
 }
 
-func writeFloat32(buff []byte, float float32) {
-	binary.LittleEndian.PutUint32(buff, math.Float32bits(float))
-}
-
-func readUint16(buff []byte, dest *uint16) error {
-	*dest = uint16(buff[0] & 0xFF)
-	*dest += uint16(buff[1]&0xFF) << 8
-	return nil
-}
-
 func readUint32(buff []byte, dest *uint32) error {
 	*dest = 0
 	for i := 0; i < 4; i++ {
@@ -162,7 +153,3 @@ func readUint64(buff []byte, dest *uint64) error {
 
 	return nil
 }
-
-func readFloat32(buff []byte, float *float32) {
-	*float = math.Float32frombits(binary.LittleEndian.Uint32(buff))
-}
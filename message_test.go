@@ -0,0 +1,94 @@
+package golifx
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// encodedMessage returns a LightState packet as sent by a bulb
+func encodedMessage(t testing.TB) []byte {
+	msg, err := makeMessageWithPayload(&protocol.LightState{Power: 65535})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg.target = 0x010000d573d0
+	msg.sequence = 3
+	return msg.ReadRaw()
+}
+
+func TestMessageWrite(t *testing.T) {
+	valid := encodedMessage(t)
+
+	wrongSize := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint16(wrongSize, uint16(len(valid)-1))
+
+	wrongProtocol := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint16(wrongProtocol[2:], 0x1400|1)
+
+	truncated := append([]byte(nil), valid[:_DEFAULT_HEADER_LENGTH+10]...)
+	binary.LittleEndian.PutUint16(truncated, uint16(len(truncated)))
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, protocol.ErrShortHeader},
+		{"short header", valid[:10], protocol.ErrShortHeader},
+		{"wrong size", wrongSize, protocol.ErrSizeMismatch},
+		{"wrong protocol", wrongProtocol, protocol.ErrProtocolNumber},
+		{"truncated payload", truncated, protocol.ErrShortPayload},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := makeMessage().Write(test.data)
+
+			var decodeErr *DecodeError
+
+			if n != 0 || !errors.As(err, &decodeErr) || !errors.Is(err, test.err) {
+				t.Fatalf("got %d, %v, want *DecodeError %v", n, err, test.err)
+			}
+		})
+	}
+
+	msg := makeMessage()
+
+	if n, err := msg.Write(valid); err != nil || n != len(valid) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+
+	if msg._type != protocol.TypeLightState || msg.target != 0x010000d573d0 || msg.sequence != 3 || len(msg.payout) != 52 {
+		t.Fatalf("decoded %+v %+v", msg.header, msg.payout)
+	}
+}
+
+func FuzzMessageWrite(f *testing.F) {
+	valid := encodedMessage(f)
+
+	f.Add(valid)
+	f.Add(valid[:10])
+	f.Add(valid[:_DEFAULT_HEADER_LENGTH])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		n, err := makeMessage().Write(data)
+
+		if err == nil {
+			if n != len(data) {
+				t.Fatalf("wrote %d of %d bytes", n, len(data))
+			}
+			return
+		}
+
+		var decodeErr *DecodeError
+
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("got %T %v, want *DecodeError", err, err)
+		}
+	})
+}
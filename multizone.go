@@ -0,0 +1,96 @@
+package golifx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+var (
+	// ErrIncompleteResponse is returned when some packets of a multi-packet
+	// response did not arrive
+	ErrIncompleteResponse = errors.New("Incomplete multi-packet response")
+	// ErrInvalidZoneRange is returned by GetColorZones when the end index is
+	// below the start index
+	ErrInvalidZoneRange = errors.New("End zone index is below start index")
+)
+
+func (b *Bulb) GetColorZones(startIndex, endIndex uint8) ([]HSBK, error) {
+	return b.GetColorZonesContext(context.Background(), startIndex, endIndex)
+}
+
+// GetColorZonesContext returns the colors of the zones from startIndex to
+// endIndex of a multizone strip, endIndex is capped to the last zone. The
+// strip answers with one StateMultiZone per eight zones.
+func (b *Bulb) GetColorZonesContext(ctx context.Context, startIndex, endIndex uint8) ([]HSBK, error) {
+	// The strip does not answer such a request at all
+	if endIndex < startIndex {
+		return nil, ErrInvalidZoneRange
+	}
+
+	msg, err := makeMessageWithPayload(&protocol.MultiZoneGetColorZones{
+		StartIndex: startIndex,
+		EndIndex:   endIndex,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	msg.res_required = true
+
+	// A single zone is answered with StateZone
+	if startIndex == endIndex {
+		state := &protocol.MultiZoneStateZone{}
+		reply, err := b.sendAndReceive(ctx, msg, state.Type())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := state.UnmarshalBinary(reply.payout); err != nil {
+			return nil, err
+		}
+
+		return []HSBK{hsbkFromProtocol(state.Color)}, nil
+	}
+
+	replies, err := b.sendAndCollect(ctx, msg, protocol.TypeMultiZoneStateMultiZone, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	zones := map[int]HSBK{}
+	last := int(endIndex)
+
+	for _, reply := range replies {
+		state := &protocol.MultiZoneStateMultiZone{}
+
+		if err := state.UnmarshalBinary(reply.payout); err != nil {
+			return nil, err
+		}
+
+		if int(state.Count)-1 < last {
+			last = int(state.Count) - 1
+		}
+
+		for i, color := range state.Colors {
+			zones[int(state.Index)+i] = hsbkFromProtocol(color)
+		}
+	}
+
+	colors := []HSBK{}
+
+	for i := int(startIndex); i <= last; i++ {
+		color, ok := zones[i]
+
+		if !ok {
+			return nil, ErrIncompleteResponse
+		}
+		colors = append(colors, color)
+	}
+
+	return colors, nil
+}
@@ -1,66 +1,495 @@
 package golifx
 
 import (
+	"context"
+	"errors"
 	"net"
+	"sync"
 	"time"
+
+	"github.com/2tvenom/golifx/protocol"
 )
 
-type connection struct {
-	bcastAddress net.IP
-}
+type (
+	connection struct {
+		bcastAddress net.IP
+		port         int
+		localAddr    *net.UDPAddr
+		interfaces   bool
+		listenPort   bool
+		transport    Transport
+		middleware   []Middleware
+
+		mu          sync.Mutex
+		closed      bool
+		links       map[string]*link
+		sequence    uint8
+		pending     map[uint8]*request
+		listener    *link
+		subscribers []chan *message
+
+		interfaceCache   []interfaceNet
+		interfacesListed time.Time
+	}
+
+	// link is a socket the connection sends and receives through. The
+	// default link is bound to the configured local address, every other
+	// link is bound to an IPv4 address of a network interface. Links are
+	// keyed by interface and address as an interface may have several.
+	link struct {
+		key       string
+		iface     string
+		transport Transport
+		network   *net.IPNet
+		broadcast *net.UDPAddr
+		listener  bool
+	}
+
+	// request is an outstanding message waiting for replies from the read loop
+	request struct {
+		source      uint32
+		target      uint64
+		messageType uint16
+		ack         bool
+		response    bool
+		responses   chan *message
+	}
+)
 
 const (
 	_DEFAULT_MAX_DEAD_LINE = time.Millisecond * 500
 	_DEFAULT_PORT          = 56700
+	_MAX_PENDING_RESPONSES = 256
+	// _MAX_PACKET_SIZE is the largest packet received, the biggest LIFX
+	// messages (extended multizone and tile states) are below 900 bytes
+	_MAX_PACKET_SIZE = 1500
+	// _DEFAULT_QUIET_PERIOD ends a multi-packet response when no further
+	// packet arrives within it
+	_DEFAULT_QUIET_PERIOD = time.Millisecond * 100
 )
 
-func (c *connection) get() (*net.UDPConn, error) {
-	udpConn, err := net.ListenPacket("udp", ":0")
+var (
+	// ErrConnectionClosed is returned for requests in flight when the socket is closed
+	ErrConnectionClosed = errors.New("Connection closed")
+	// ErrTooManyRequests is returned when every sequence number is in use
+	ErrTooManyRequests = errors.New("Too many requests in flight")
+)
 
-	if err != nil {
+// open returns the default link, creating it and starting its read loop on
+// first use. ErrConnectionClosed is returned once the connection is closed.
+func (c *connection) open() (*link, error) {
+	return c.openLink("", c.localAddr)
+}
+
+// openLink returns the link for the interface address, binding a new socket
+// to addr if there is none yet. The default link uses the configured
+// transport.
+func (c *connection) openLink(iface string, addr *net.UDPAddr) (*link, error) {
+	key := linkKey(iface, addr)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrConnectionClosed
+	}
+
+	if l, ok := c.links[key]; ok {
+		return l, nil
+	}
+
+	var (
+		transport Transport
+		err       error
+	)
+
+	if iface == "" && c.transport != nil {
+		transport = c.wrap(c.transport)
+	} else if transport, err = c.listen(addr); err != nil {
 		return nil, err
 	}
 
-	conn := udpConn.(*net.UDPConn)
-	return conn, nil
+	if c.links == nil {
+		c.links = map[string]*link{}
+	}
+
+	if c.pending == nil {
+		c.pending = map[uint8]*request{}
+	}
+
+	l := &link{key: key, iface: iface, transport: transport}
+	c.links[key] = l
+
+	go c.readLoop(l)
+
+	return l, nil
 }
 
-func (c *connection) sendAndReceive(inMessage *message) ([]*message, error) {
-	return c.sendAndReceiveDead(inMessage, _DEFAULT_MAX_DEAD_LINE)
+// linkKey returns the key of the link bound to addr on the interface, the
+// default link has the empty key
+func linkKey(iface string, addr *net.UDPAddr) string {
+	if iface == "" {
+		return ""
+	}
+	return iface + "/" + addr.IP.String()
 }
 
-func (c *connection) sendAndReceiveDead(inMessage *message, deadline time.Duration) ([]*message, error) {
-	conn, err := c.get()
-	conn.SetReadDeadline(time.Now().Add(deadline))
-	defer conn.Close()
+// link returns the link with the key, falling back to the default one when
+// the link is unknown or its socket has gone
+func (c *connection) link(key string) (*link, error) {
+	if key != "" {
+		c.mu.Lock()
+		l, ok := c.links[key]
+		c.mu.Unlock()
 
-	if err != nil {
+		if ok {
+			return l, nil
+		}
+	}
+
+	return c.open()
+}
+
+// close closes every socket, the connection cannot be used afterwards
+func (c *connection) close() error {
+	c.mu.Lock()
+	c.closed = true
+	links := []*link{}
+
+	for _, l := range c.links {
+		links = append(links, l)
+	}
+
+	if c.listener != nil {
+		links = append(links, c.listener)
+	}
+
+	c.links = nil
+	c.listener = nil
+	c.mu.Unlock()
+
+	var err error
+
+	for _, l := range links {
+		if closeErr := l.transport.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// readLoop receives the packets of the link into a buffer of its own,
+// decoded messages copy their payload out of it
+func (c *connection) readLoop(l *link) {
+	buff := make([]byte, _MAX_PACKET_SIZE)
+
+	for {
+		n, addr, err := l.transport.ReadFrom(buff)
+
+		if err != nil {
+			if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
+				continue
+			}
+
+			c.mu.Lock()
+			if l.listener {
+				if c.listener == l {
+					c.listener = nil
+				}
+				c.mu.Unlock()
+				return
+			}
+
+			if c.links[l.key] == l {
+				delete(c.links, l.key)
+			}
+
+			// Requests are still answered through the interface links
+			// while the default one is alive
+			if l.key == "" || len(c.links) == 0 {
+				for sequence, req := range c.pending {
+					close(req.responses)
+					delete(c.pending, sequence)
+				}
+			}
+			c.mu.Unlock()
+			return
+		}
+
+		// A datagram filling the whole buffer may have been truncated
+		if n == len(buff) {
+			continue
+		}
+
+		msg := makeMessage()
+
+		// Stray, malformed or truncated packets are dropped, the size field
+		// has to match the number of bytes received
+		if _, err := msg.Write(buff[:n]); err != nil {
+			continue
+		}
+
+		msg.addr = addr
+		msg.iface = l.iface
+		msg.link = l.key
+
+		c.dispatch(msg)
+	}
+}
+
+// dispatch hands the message to the request it answers, anything else
+// (state broadcasts, replies to other clients, late replies to finished
+// requests) goes to the subscribers
+func (c *connection) dispatch(msg *message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if req, ok := c.pending[msg.sequence]; ok && req.accepts(msg) {
+		select {
+		case req.responses <- msg:
+		default:
+		}
+		return
+	}
+
+	for _, messages := range c.subscribers {
+		select {
+		case messages <- msg:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving the messages no request is waiting
+// for. The socket listening on the bulb port is opened with the first
+// subscriber and closed with the last one, the error is returned when the
+// port is taken, e.g. by an emulator or another subscribing process.
+func (c *connection) subscribe() (chan *message, error) {
+	if _, err := c.open(); err != nil {
 		return nil, err
 	}
 
-	_, err = conn.WriteTo(inMessage.ReadRaw(), &net.UDPAddr{
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrConnectionClosed
+	}
+
+	// A client bound to the bulb port receives the broadcasts already, a
+	// custom transport gets whatever it is given
+	listening := !c.listenPort || c.transport != nil || c.localAddr != nil && c.localAddr.Port == c.port
+
+	if c.listener == nil && !listening {
+		transport, err := c.listen(&net.UDPAddr{Port: c.port})
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.listener = &link{transport: transport, listener: true}
+		go c.readLoop(c.listener)
+	}
+
+	messages := make(chan *message, _MAX_PENDING_RESPONSES)
+	c.subscribers = append(c.subscribers, messages)
+
+	return messages, nil
+}
+
+func (c *connection) unsubscribe(messages chan *message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, subscriber := range c.subscribers {
+		if subscriber == messages {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			break
+		}
+	}
+
+	if len(c.subscribers) == 0 && c.listener != nil {
+		c.listener.transport.Close()
+		c.listener = nil
+	}
+}
+
+// register allocates the next free sequence number for the message, wrapping
+// at 255, and starts waiting for its replies
+func (c *connection) register(msg *message) (*request, error) {
+	if _, err := c.open(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := 0; i < 256; i++ {
+		c.sequence++
+
+		if _, busy := c.pending[c.sequence]; busy {
+			continue
+		}
+
+		msg.sequence = c.sequence
+		req := &request{
+			source:      msg.source,
+			target:      msg.target,
+			messageType: msg._type,
+			ack:         msg.ack_required,
+			response:    msg.res_required,
+			responses:   make(chan *message, _MAX_PENDING_RESPONSES),
+		}
+		c.pending[msg.sequence] = req
+		return req, nil
+	}
+
+	return nil, ErrTooManyRequests
+}
+
+func (r *request) accepts(msg *message) bool {
+	if r.source != msg.source {
+		return false
+	}
+
+	if r.target != 0 && r.target != msg.target {
+		return false
+	}
+
+	// Our own broadcasts come back through the listener
+	if msg._type == r.messageType {
+		return false
+	}
+
+	// StateUnhandled answers both kinds of requests
+	if msg._type == protocol.TypeDeviceStateUnhandled {
+		return true
+	}
+
+	if msg._type == protocol.TypeDeviceAcknowledgement {
+		return r.ack
+	}
+
+	// Without any flag set the message is a Get expecting its State
+	return r.response || !r.ack
+}
+
+func (c *connection) unregister(msg *message, req *request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending[msg.sequence] == req {
+		delete(c.pending, msg.sequence)
+	}
+}
+
+func (c *connection) broadcastAddr() *net.UDPAddr {
+	return &net.UDPAddr{
 		IP:   c.bcastAddress,
-		Port: _DEFAULT_PORT,
-	})
+		Port: c.port,
+	}
+}
+
+// send registers the message and writes it to addr through the link
+func (c *connection) send(inMessage *message, link string, addr *net.UDPAddr) (*request, error) {
+	req, err := c.register(inMessage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.write(link, inMessage.ReadRaw(), addr)
 
 	if err != nil {
+		c.unregister(inMessage, req)
 		return nil, err
 	}
 
+	return req, nil
+}
+
+// write sends an encoded message to addr through the link with the key, an
+// empty key means the default socket
+func (c *connection) write(key string, raw []byte, addr *net.UDPAddr) error {
+	l, err := c.link(key)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = l.transport.WriteTo(raw, addr)
+	return err
+}
+
+// sendAndReceiveAddr writes the message to addr and collects every reply
+// received until ctx is done
+func (c *connection) sendAndReceiveAddr(ctx context.Context, inMessage *message, link string, addr *net.UDPAddr) ([]*message, error) {
+	req, err := c.send(inMessage, link, addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.unregister(inMessage, req)
+
 	messages := []*message{}
 
 	for {
-		buff := make([]byte, 512)
-		n, addr, err := conn.ReadFrom(buff)
-		if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-			return messages, nil
+		select {
+		case msg, ok := <-req.responses:
+			if !ok {
+				return messages, ErrConnectionClosed
+			}
+			messages = append(messages, msg)
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return messages, nil
+			}
+			return messages, ctx.Err()
 		}
+	}
+}
 
-		msg := makeMessage()
-		msg.Write(buff[:n])
-		msg.addr = addr
+// wait returns the first reply to the request, ErrTimeout is returned if
+// nothing arrives before ctx is done
+func (c *connection) wait(ctx context.Context, req *request) (*message, error) {
+	select {
+	case msg, ok := <-req.responses:
+		if !ok {
+			return nil, ErrConnectionClosed
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ErrTimeout
+	}
+}
+
+// collect returns the replies to the request in the order they arrived. It
+// stops once count replies are collected (zero means no limit), when nothing
+// more arrives for the quiet period or when ctx is done. ErrTimeout is
+// returned if there was no reply at all.
+func (c *connection) collect(ctx context.Context, req *request, count int, quiet time.Duration) ([]*message, error) {
+	messages := []*message{}
 
-		messages = append(messages, msg)
+	var silence <-chan time.Time
+
+	for count == 0 || len(messages) < count {
+		select {
+		case msg, ok := <-req.responses:
+			if !ok {
+				return messages, ErrConnectionClosed
+			}
+			messages = append(messages, msg)
+			silence = time.After(quiet)
+		case <-silence:
+			return messages, nil
+		case <-ctx.Done():
+			if len(messages) == 0 {
+				return nil, ErrTimeout
+			}
+			return messages, nil
+		}
 	}
 
 	return messages, nil
@@ -0,0 +1,91 @@
+package golifx
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+func TestReadLoopDropsInvalidPackets(t *testing.T) {
+	network := NewMemoryNetwork()
+	transport, _ := network.Listen(nil)
+	device, _ := network.Listen(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: _DEFAULT_PORT})
+	defer device.Close()
+
+	client := NewClient(WithTransport(transport))
+	defer client.Close()
+
+	msg := client.makeMessage()
+	msg._type = protocol.TypeDeviceGetPower
+	msg.target = _TEST_MAC
+	msg.res_required = true
+
+	req, err := client.conn.register(msg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.conn.unregister(msg, req)
+
+	// reply returns a StatePower answering msg, padded to size bytes with a
+	// matching size field
+	reply := func(level uint16, size int) []byte {
+		packet := protocol.NewPacket(&protocol.DeviceStatePower{Level: level})
+		packet.Source = msg.source
+		packet.Target = _TEST_MAC
+		packet.Sequence = msg.sequence
+
+		data, err := packet.MarshalBinary()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if size > len(data) {
+			data = append(data, make([]byte, size-len(data))...)
+			binary.LittleEndian.PutUint16(data, uint16(size))
+		}
+
+		return data
+	}
+
+	mismatch := reply(2, 0)
+	binary.LittleEndian.PutUint16(mismatch, uint16(len(mismatch)+1))
+
+	packets := [][]byte{
+		// A datagram filling the buffer may have been truncated
+		reply(1, _MAX_PACKET_SIZE),
+		mismatch,
+		reply(3, 0),
+		reply(4, _MAX_PACKET_SIZE-1),
+	}
+
+	for _, data := range packets {
+		if _, err := device.WriteTo(data, transport.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []uint16{3, 4} {
+		select {
+		case m := <-req.responses:
+			state := &protocol.DeviceStatePower{}
+
+			if err := state.UnmarshalBinary(m.payout); err != nil || state.Level != want {
+				t.Fatalf("got level %d, %v, want %d", state.Level, err, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no reply with level %d", want)
+		}
+	}
+
+	select {
+	case m := <-req.responses:
+		t.Fatalf("got another reply %+v", m.header)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
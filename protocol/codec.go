@@ -0,0 +1,69 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// codec is implemented by every fixed size payload and field type
+type codec interface {
+	Size() int
+	encode(data []byte)
+	decode(data []byte)
+}
+
+func marshal(c codec) ([]byte, error) {
+	data := make([]byte, c.Size())
+	c.encode(data)
+	return data, nil
+}
+
+// marshalTo encodes c into the first Size bytes of data, io.ErrShortBuffer
+// is returned if data is shorter
+func marshalTo(c codec, data []byte) (int, error) {
+	size := c.Size()
+
+	if len(data) < size {
+		return 0, io.ErrShortBuffer
+	}
+
+	data = data[:size]
+
+	for i := range data {
+		data[i] = 0
+	}
+
+	c.encode(data)
+	return size, nil
+}
+
+func unmarshal(c codec, data []byte) error {
+	if len(data) < c.Size() {
+		err := &DecodeError{Length: len(data), Want: c.Size(), Err: ErrShortPayload}
+
+		if payload, ok := c.(Payload); ok {
+			err.Type = payload.Type()
+		}
+		return err
+	}
+
+	c.decode(data)
+	return nil
+}
+
+func putBool(data []byte, v bool) {
+	data[0] = boolToUint8(v)
+}
+
+func getBool(data []byte) bool {
+	return data[0] != 0
+}
+
+func putFloat32(data []byte, v float32) {
+	binary.LittleEndian.PutUint32(data, math.Float32bits(v))
+}
+
+func getFloat32(data []byte) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data))
+}
@@ -0,0 +1,15 @@
+// Package protocol implements encoding and decoding of LIFX LAN protocol
+// packets. It is used by golifx and can be used to build sniffers,
+// simulators or proxies speaking the same protocol.
+//
+// A packet is a Header followed by a Payload. Payload types are looked up
+// by message type number in a registry, unknown types decode as Raw.
+//
+// Message types, payloads and enums are generated from protocol.yml, a copy
+// of the machine readable protocol definition published by LIFX. Edit it
+// and run go generate to add messages.
+//
+// Protocol specification: https://lan.developer.lifx.com/
+package protocol
+
+//go:generate go run ./gen -in protocol.yml -out .
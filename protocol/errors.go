@@ -0,0 +1,52 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DecodeError describes why data could not be decoded. Err is one of the
+// Err* values of this package and can be tested with errors.Is.
+type DecodeError struct {
+	// Type is the message type, zero when the header itself is invalid
+	Type uint16
+	// Length is the number of bytes that were decoded
+	Length int
+	// Want is the number of bytes required, zero when not relevant
+	Want int
+	Err  error
+}
+
+var (
+	// ErrShortHeader is returned when data is shorter than HeaderLength
+	ErrShortHeader = errors.New("Packet is shorter than header")
+	// ErrSizeMismatch is returned when the header size field does not match
+	// the number of bytes received
+	ErrSizeMismatch = errors.New("Packet size field does not match its length")
+	// ErrProtocolNumber is returned for packets with a protocol number other
+	// than ProtocolNumber
+	ErrProtocolNumber = errors.New("Unsupported protocol number")
+	// ErrShortPayload is returned when a payload is shorter than its message
+	// type requires
+	ErrShortPayload = errors.New("Payload is too short for message type")
+)
+
+func (e *DecodeError) Error() string {
+	str := e.Err.Error()
+
+	if e.Type != 0 {
+		str += fmt.Sprintf(" (type %d)", e.Type)
+	}
+
+	if e.Want != 0 {
+		str += fmt.Sprintf(": got %d bytes, want %d", e.Length, e.Want)
+	} else {
+		str += fmt.Sprintf(": got %d bytes", e.Length)
+	}
+
+	return str
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,633 @@
+// Command gen generates the message types, payload structs, enums and codec
+// of package protocol from protocol.yml.
+//
+//	go run ./gen -in protocol.yml -out .
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	enum struct {
+		Name   string
+		Type   string
+		Values []enumValue
+	}
+
+	enumValue struct {
+		Name  string
+		Label string
+		Value int
+	}
+
+	field struct {
+		// Name is empty for reserved fields
+		Name string
+		// Type is the protocol.yml type, e.g. uint16, [32]byte or <LightHsbk>
+		Type string
+		Size int
+	}
+
+	structDef struct {
+		Name   string
+		Type   int
+		Packet bool
+		Size   int
+		Fields []field
+	}
+
+	namespace struct {
+		Name    string
+		Packets []*structDef
+	}
+
+	spec struct {
+		Enums      []*enum
+		Fields     []*structDef
+		Namespaces []*namespace
+
+		enums  map[string]*enum
+		fields map[string]*structDef
+	}
+)
+
+var scalarSizes = map[string]int{
+	"bool":    1,
+	"uint8":   1,
+	"int8":    1,
+	"uint16":  2,
+	"int16":   2,
+	"uint32":  4,
+	"int32":   4,
+	"uint64":  8,
+	"int64":   8,
+	"float32": 4,
+}
+
+var initialisms = map[string]bool{
+	"UDP": true,
+}
+
+func main() {
+	in := flag.String("in", "protocol.yml", "protocol definition")
+	out := flag.String("out", ".", "output directory")
+	flag.Parse()
+
+	data, err := os.ReadFile(*in)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := load(string(data))
+
+	if err != nil {
+		log.Fatalf("%s: %s", *in, err)
+	}
+
+	files, err := s.generate()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(*out, name), src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generate returns the formatted source of every generated file by name
+func (s *spec) generate() (map[string][]byte, error) {
+	files := map[string][]byte{
+		"types_gen.go": s.types(),
+	}
+
+	for _, ns := range s.Namespaces {
+		files[strings.Replace(ns.Name, "_", "", -1)+"_gen.go"] = s.packets(ns)
+	}
+
+	for name, src := range files {
+		formatted, err := format.Source(src)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s\n%s", name, err, src)
+		}
+
+		files[name] = formatted
+	}
+
+	return files, nil
+}
+
+func load(data string) (*spec, error) {
+	root, err := parseYAML(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spec{enums: map[string]*enum{}, fields: map[string]*structDef{}}
+
+	enums := root.get("enums")
+
+	for _, name := range keys(enums) {
+		n := enums.get(name)
+		e := &enum{Name: name, Type: n.str("type")}
+
+		if _, ok := scalarSizes[e.Type]; !ok {
+			return nil, fmt.Errorf("enum %s: unsupported type %q", name, e.Type)
+		}
+
+		prefix := upperSnake(name) + "_"
+
+		for _, v := range items(n.get("values")) {
+			value, err := strconv.Atoi(v.str("value"))
+
+			if err != nil {
+				return nil, fmt.Errorf("enum %s: %s", name, err)
+			}
+
+			if v.str("name") == "reserved" {
+				continue
+			}
+
+			label := strings.ToLower(strings.TrimPrefix(v.str("name"), prefix))
+			e.Values = append(e.Values, enumValue{Name: goName(v.str("name")), Label: label, Value: value})
+		}
+
+		s.Enums = append(s.Enums, e)
+		s.enums[name] = e
+	}
+
+	fields := root.get("fields")
+
+	for _, name := range keys(fields) {
+		def, err := s.structDef(name, fields.get(name), false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Fields = append(s.Fields, def)
+		s.fields[name] = def
+	}
+
+	packets := root.get("packets")
+
+	for _, nsName := range keys(packets) {
+		ns := &namespace{Name: nsName}
+		n := packets.get(nsName)
+
+		for _, name := range keys(n) {
+			def, err := s.structDef(name, n.get(name), true)
+
+			if err != nil {
+				return nil, err
+			}
+			ns.Packets = append(ns.Packets, def)
+		}
+
+		sort.SliceStable(ns.Packets, func(i, j int) bool { return ns.Packets[i].Type < ns.Packets[j].Type })
+		s.Namespaces = append(s.Namespaces, ns)
+	}
+
+	return s, nil
+}
+
+func (s *spec) structDef(name string, n *node, packet bool) (*structDef, error) {
+	def := &structDef{Name: name, Packet: packet}
+
+	if packet {
+		t, err := strconv.Atoi(n.str("pkt_type"))
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: pkt_type: %s", name, err)
+		}
+		def.Type = t
+	}
+
+	for _, f := range items(n.get("fields")) {
+		size, err := strconv.Atoi(f.str("size_bytes"))
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: size_bytes: %s", name, err)
+		}
+
+		fd := field{Type: f.str("type"), Size: size}
+
+		if fd.Type != "reserved" {
+			fd.Name = goName(f.str("name"))
+
+			want, err := s.typeSize(fd.Type)
+
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", name, fd.Name, err)
+			}
+
+			if want != size {
+				return nil, fmt.Errorf("%s.%s: size_bytes is %d, type %s takes %d", name, fd.Name, size, fd.Type, want)
+			}
+		}
+
+		def.Fields = append(def.Fields, fd)
+		def.Size += size
+	}
+
+	if declared, err := strconv.Atoi(n.str("size_bytes")); err != nil || declared != def.Size {
+		return nil, fmt.Errorf("%s: size_bytes is %q, fields take %d", name, n.str("size_bytes"), def.Size)
+	}
+
+	return def, nil
+}
+
+// typeSize returns the encoded size of a protocol.yml type
+func (s *spec) typeSize(t string) (int, error) {
+	if size, ok := scalarSizes[t]; ok {
+		return size, nil
+	}
+
+	if count, elem, ok := arrayType(t); ok {
+		if elem == "byte" {
+			return count, nil
+		}
+
+		size, err := s.typeSize(elem)
+		return count * size, err
+	}
+
+	if name, ok := refType(t); ok {
+		if e, ok := s.enums[name]; ok {
+			return scalarSizes[e.Type], nil
+		}
+
+		if f, ok := s.fields[name]; ok {
+			return f.Size, nil
+		}
+
+		return 0, fmt.Errorf("unknown type %s", name)
+	}
+
+	return 0, fmt.Errorf("unsupported type %q", t)
+}
+
+// goType returns the Go type of a protocol.yml type
+func goType(t string) string {
+	if count, elem, ok := arrayType(t); ok {
+		return fmt.Sprintf("[%d]%s", count, goType(elem))
+	}
+
+	if name, ok := refType(t); ok {
+		return name
+	}
+
+	return t
+}
+
+func arrayType(t string) (int, string, bool) {
+	if !strings.HasPrefix(t, "[") {
+		return 0, "", false
+	}
+
+	end := strings.Index(t, "]")
+
+	if end < 0 {
+		return 0, "", false
+	}
+
+	count, err := strconv.Atoi(t[1:end])
+
+	if err != nil {
+		return 0, "", false
+	}
+
+	return count, t[end+1:], true
+}
+
+func refType(t string) (string, bool) {
+	if strings.HasPrefix(t, "<") && strings.HasSuffix(t, ">") {
+		return t[1 : len(t)-1], true
+	}
+	return "", false
+}
+
+// types generates enums and field structs
+func (s *spec) types() []byte {
+	g := &generator{spec: s}
+	g.header([]string{"fmt"})
+
+	for _, e := range s.Enums {
+		g.p("// %s is the %s enum of the LIFX protocol", e.Name, e.Name)
+		g.p("type %s %s\n", e.Name, e.Type)
+		g.p("const (")
+
+		for _, v := range e.Values {
+			g.p("%s %s = %d", v.Name, e.Name, v.Value)
+		}
+
+		g.p(")\n")
+		g.p("func (v %s) String() string {", e.Name)
+		g.p("switch v {")
+
+		for _, v := range e.Values {
+			g.p("case %s:", v.Name)
+			g.p("return %q", v.Label)
+		}
+
+		g.p("}")
+		g.p("return fmt.Sprintf(\"%s(%%d)\", v)", e.Name)
+		g.p("}\n")
+	}
+
+	g.p("type (")
+
+	for _, def := range s.Fields {
+		g.p("// %s is a field type of the LIFX protocol", def.Name)
+		g.structType(def)
+	}
+
+	g.p(")\n")
+
+	for _, def := range s.Fields {
+		g.p("func (p *%s) MarshalBinary() ([]byte, error) { return marshal(p) }\n", def.Name)
+		g.p("func (p *%s) UnmarshalBinary(data []byte) error { return unmarshal(p, data) }\n", def.Name)
+		g.codec(def)
+	}
+
+	g.p("var typeNames = map[uint16]string{")
+
+	for _, ns := range s.Namespaces {
+		for _, def := range ns.Packets {
+			g.p("%d: %q,", def.Type, def.Name)
+		}
+	}
+
+	g.p("}")
+
+	return g.Bytes()
+}
+
+// packets generates the payloads of a namespace
+func (s *spec) packets(ns *namespace) []byte {
+	g := &generator{spec: s}
+	g.header(nil)
+
+	g.p("const (")
+
+	for _, def := range ns.Packets {
+		g.p("Type%s uint16 = %d", def.Name, def.Type)
+	}
+
+	g.p(")\n")
+	g.p("type (")
+
+	for _, def := range ns.Packets {
+		g.p("// %s is message type %d", def.Name, def.Type)
+		g.structType(def)
+	}
+
+	g.p(")\n")
+	g.p("func init() {")
+
+	for _, def := range ns.Packets {
+		g.p("Register(Type%s, func() Payload { return &%s{} })", def.Name, def.Name)
+	}
+
+	g.p("}\n")
+
+	for _, def := range ns.Packets {
+		g.p("func (*%s) Type() uint16 { return Type%s }\n", def.Name, def.Name)
+		g.p("func (p *%s) MarshalBinary() ([]byte, error) { return marshal(p) }\n", def.Name)
+		g.p("// MarshalTo encodes the payload into data without allocating")
+		g.p("func (p *%s) MarshalTo(data []byte) (int, error) { return marshalTo(p, data) }\n", def.Name)
+		g.p("func (p *%s) UnmarshalBinary(data []byte) error { return unmarshal(p, data) }\n", def.Name)
+		g.codec(def)
+	}
+
+	return g.Bytes()
+}
+
+type generator struct {
+	bytes.Buffer
+	spec       *spec
+	usesBinary bool
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(g, format, args...)
+	g.WriteString("\n")
+}
+
+func (g *generator) header(imports []string) {
+	g.p("// Code generated by gen from protocol.yml. DO NOT EDIT.\n")
+	g.p("package protocol\n")
+
+	if len(imports) > 0 {
+		g.p("import (")
+
+		for _, imp := range imports {
+			g.p("%q", imp)
+		}
+
+		g.p(")\n")
+	}
+}
+
+// Bytes adds the encoding/binary import when the generated code uses it
+func (g *generator) Bytes() []byte {
+	src := g.Buffer.Bytes()
+
+	if !bytes.Contains(src, []byte("binary.LittleEndian")) {
+		return src
+	}
+
+	if bytes.Contains(src, []byte("import (\n")) {
+		return bytes.Replace(src, []byte("import (\n"), []byte("import (\n\"encoding/binary\"\n"), 1)
+	}
+
+	return bytes.Replace(src, []byte("package protocol\n"), []byte("package protocol\n\nimport \"encoding/binary\"\n"), 1)
+}
+
+func (g *generator) structType(def *structDef) {
+	named := 0
+
+	for _, f := range def.Fields {
+		if f.Name != "" {
+			named++
+		}
+	}
+
+	if named == 0 {
+		g.p("%s struct{}\n", def.Name)
+		return
+	}
+
+	g.p("%s struct {", def.Name)
+
+	for _, f := range def.Fields {
+		if f.Name != "" {
+			g.p("%s %s", f.Name, goType(f.Type))
+		}
+	}
+
+	g.p("}\n")
+}
+
+func (g *generator) codec(def *structDef) {
+	g.p("func (*%s) Size() int { return %d }\n", def.Name, def.Size)
+
+	var enc, dec bytes.Buffer
+
+	offset := 0
+
+	for _, f := range def.Fields {
+		if f.Name != "" {
+			g.field(&enc, &dec, "p."+f.Name, f.Type, offset)
+		}
+		offset += f.Size
+	}
+
+	if enc.Len() == 0 {
+		g.p("func (*%s) encode(data []byte) {}\n", def.Name)
+		g.p("func (*%s) decode(data []byte) {}\n", def.Name)
+		return
+	}
+
+	g.p("func (p *%s) encode(data []byte) {\n%s}\n", def.Name, enc.String())
+	g.p("func (p *%s) decode(data []byte) {\n%s}\n", def.Name, dec.String())
+}
+
+// field writes the statements encoding and decoding a value of type t at
+// offset
+func (g *generator) field(enc, dec *bytes.Buffer, name, t string, offset int) {
+	size, _ := g.spec.typeSize(t)
+	slice := fmt.Sprintf("data[%d:%d]", offset, offset+size)
+
+	if count, elem, ok := arrayType(t); ok {
+		if elem == "byte" {
+			fmt.Fprintf(enc, "copy(%s, %s[:])\n", slice, name)
+			fmt.Fprintf(dec, "copy(%s[:], %s)\n", name, slice)
+			return
+		}
+
+		elemSize := size / count
+		fmt.Fprintf(enc, "for i := range %s {\n", name)
+		fmt.Fprintf(dec, "for i := range %s {\n", name)
+		g.element(enc, dec, name+"[i]", elem, fmt.Sprintf("data[%d+i*%d : %d+(i+1)*%d]", offset, elemSize, offset, elemSize))
+		enc.WriteString("}\n")
+		dec.WriteString("}\n")
+		return
+	}
+
+	g.element(enc, dec, name, t, slice)
+}
+
+func (g *generator) element(enc, dec *bytes.Buffer, name, t, slice string) {
+	if ref, ok := refType(t); ok {
+		if e, ok := g.spec.enums[ref]; ok {
+			g.scalar(enc, dec, name, e.Type, slice, e.Name)
+			return
+		}
+
+		fmt.Fprintf(enc, "%s.encode(%s)\n", name, slice)
+		fmt.Fprintf(dec, "%s.decode(%s)\n", name, slice)
+		return
+	}
+
+	g.scalar(enc, dec, name, t, slice, t)
+}
+
+// scalar writes the statements for a scalar of wire type t held in a Go
+// value of type goT
+func (g *generator) scalar(enc, dec *bytes.Buffer, name, t, slice, goT string) {
+	switch t {
+	case "bool":
+		fmt.Fprintf(enc, "putBool(%s, %s)\n", slice, name)
+		fmt.Fprintf(dec, "%s = getBool(%s)\n", name, slice)
+	case "float32":
+		fmt.Fprintf(enc, "putFloat32(%s, %s)\n", slice, name)
+		fmt.Fprintf(dec, "%s = getFloat32(%s)\n", name, slice)
+	case "uint8", "int8":
+		first := slice[:strings.Index(slice, ":")] + "]"
+		fmt.Fprintf(enc, "%s = %s\n", first, convert("uint8", goT, name))
+		fmt.Fprintf(dec, "%s = %s\n", name, convert(goT, "uint8", first))
+	default:
+		bits := strings.TrimLeft(t, "uint")
+		wire := "uint" + bits
+		fmt.Fprintf(enc, "binary.LittleEndian.PutUint%s(%s, %s)\n", bits, slice, convert(wire, goT, name))
+		fmt.Fprintf(dec, "%s = %s\n", name, convert(goT, wire, fmt.Sprintf("binary.LittleEndian.Uint%s(%s)", bits, slice)))
+	}
+}
+
+func convert(to, from, expr string) string {
+	if to == from {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+// goName converts protocol.yml names such as Skew_Ratio or
+// LIGHT_WAVEFORM_SINE into Go identifiers
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	allUpper := strings.ToUpper(name) == name
+
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		if initialisms[strings.ToUpper(part)] {
+			parts[i] = strings.ToUpper(part)
+			continue
+		}
+
+		if allUpper {
+			part = strings.ToLower(part)
+		}
+
+		parts[i] = string(unicode.ToUpper(rune(part[0]))) + part[1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// upperSnake converts LightWaveform into LIGHT_WAVEFORM
+func upperSnake(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+func keys(n *node) []string {
+	if n == nil {
+		return nil
+	}
+	return n.keys
+}
+
+func items(n *node) []*node {
+	if n == nil {
+		return nil
+	}
+	return n.items
+}
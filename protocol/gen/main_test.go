@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedFiles checks the committed *_gen.go files are what the
+// generator produces from protocol.yml, run go generate after changing
+// either
+func TestGeneratedFiles(t *testing.T) {
+	data, err := os.ReadFile("../protocol.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := load(string(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := s.generate()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range files {
+		committed, err := os.ReadFile(filepath.Join("..", name))
+
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+
+		if !bytes.Equal(committed, src) {
+			t.Errorf("%s differs from the generator output, run go generate", name)
+		}
+	}
+
+	committed, err := filepath.Glob("../*_gen.go")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range committed {
+		if _, ok := files[filepath.Base(path)]; !ok {
+			t.Errorf("%s is not generated from protocol.yml", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// node is a YAML value: a scalar, a mapping keeping its key order or a
+// sequence. Only the block subset of YAML used by protocol.yml is supported.
+type node struct {
+	scalar  string
+	keys    []string
+	mapping map[string]*node
+	items   []*node
+}
+
+type line struct {
+	number int
+	indent int
+	text   string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func parseYAML(data string) (*node, error) {
+	p := &parser{}
+
+	for i, text := range strings.Split(data, "\n") {
+		trimmed := strings.TrimSpace(text)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		p.lines = append(p.lines, line{number: i + 1, indent: indent, text: strings.TrimRight(text[indent:], " \t\r")})
+	}
+
+	if len(p.lines) == 0 {
+		return &node{}, nil
+	}
+
+	root, err := p.block(p.lines[0].indent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+
+	return root, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	number := 0
+
+	if p.pos < len(p.lines) {
+		number = p.lines[p.pos].number
+	}
+
+	return fmt.Errorf("line %d: %s", number, fmt.Sprintf(format, args...))
+}
+
+// block parses the mapping or sequence starting at the current line
+func (p *parser) block(indent int) (*node, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "- ") || p.lines[p.pos].text == "-" {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent, "")
+}
+
+func (p *parser) sequence(indent int) (*node, error) {
+	n := &node{items: []*node{}}
+
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && strings.HasPrefix(p.lines[p.pos].text+" ", "- ") {
+		rest := strings.TrimSpace(strings.TrimPrefix(p.lines[p.pos].text, "-"))
+
+		if rest == "" {
+			p.pos++
+
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				return nil, p.errorf("empty sequence item")
+			}
+
+			item, err := p.block(p.lines[p.pos].indent)
+
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+			continue
+		}
+
+		if key, _, ok := splitKey(rest); ok && key != "" {
+			// "- key: value" starts a mapping indented by the dash
+			item, err := p.mapping(indent+2, rest)
+
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+			continue
+		}
+
+		n.items = append(n.items, scalar(rest))
+		p.pos++
+	}
+
+	return n, nil
+}
+
+// mapping parses "key: value" lines at indent, first is the text of a first
+// line already consumed from a sequence item
+func (p *parser) mapping(indent int, first string) (*node, error) {
+	n := &node{mapping: map[string]*node{}}
+
+	for {
+		var text string
+
+		if first != "" {
+			text, first = first, ""
+		} else {
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent != indent || strings.HasPrefix(p.lines[p.pos].text, "- ") {
+				return n, nil
+			}
+			text = p.lines[p.pos].text
+		}
+
+		key, value, ok := splitKey(text)
+
+		if !ok {
+			return nil, p.errorf("expected key: value, got %q", text)
+		}
+
+		if _, dup := n.mapping[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+
+		p.pos++
+
+		var child *node
+
+		if value != "" {
+			child = scalar(value)
+		} else if p.pos < len(p.lines) && (p.lines[p.pos].indent > indent ||
+			(p.lines[p.pos].indent == indent && strings.HasPrefix(p.lines[p.pos].text, "- "))) {
+			var err error
+
+			if child, err = p.block(p.lines[p.pos].indent); err != nil {
+				return nil, err
+			}
+		} else {
+			child = &node{}
+		}
+
+		n.keys = append(n.keys, key)
+		n.mapping[key] = child
+	}
+}
+
+// splitKey splits "key: value", the key may be quoted
+func splitKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "\"") {
+		end := strings.Index(text[1:], "\"")
+
+		if end < 0 {
+			return "", "", false
+		}
+
+		key := text[1 : end+1]
+		rest := text[end+2:]
+
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+
+	i := strings.Index(text, ":")
+
+	if i < 0 || (i+1 < len(text) && text[i+1] != ' ') {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+}
+
+func scalar(text string) *node {
+	if text == "[]" {
+		return &node{items: []*node{}}
+	}
+
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		text = text[1 : len(text)-1]
+	}
+
+	return &node{scalar: text}
+}
+
+func (n *node) get(key string) *node {
+	if n == nil || n.mapping == nil {
+		return nil
+	}
+	return n.mapping[key]
+}
+
+func (n *node) str(key string) string {
+	if child := n.get(key); child != nil {
+		return child.scalar
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testColor encodes as 3412 7856 bc9a ac0d
+var testColor = LightHsbk{Hue: 0x1234, Saturation: 0x5678, Brightness: 0x9abc, Kelvin: 3500}
+
+const testColorHex = "34127856bc9aac0d"
+
+// TestGoldenPayloads checks the generated encodings against payloads
+// written out by hand from the LIFX protocol documentation
+func TestGoldenPayloads(t *testing.T) {
+	label := [32]byte{}
+	copy(label[:], "Kitchen")
+
+	zones := &MultiZoneStateMultiZone{Count: 16, Index: 8}
+	zonesHex := "1008"
+
+	for i := range zones.Colors {
+		zones.Colors[i] = LightHsbk{Hue: uint16(i), Brightness: 0xffff, Kelvin: 3500}
+		zonesHex += fmt.Sprintf("%02x00", i) + "0000" + "ffff" + "ac0d"
+	}
+
+	tile := &TileState64{TileIndex: 2, Rect: TileBufferRect{X: 1, Y: 3, Width: 8}}
+	tileHex := "02" + "00010308"
+
+	for i := range tile.Colors {
+		tile.Colors[i] = testColor
+		tileHex += testColorHex
+	}
+
+	tests := []struct {
+		payload Payload
+		size    int
+		golden  string
+	}{
+		{
+			&LightSetColor{Color: testColor, Duration: 1000},
+			13,
+			"00" + testColorHex + "e8030000",
+		},
+		{
+			&LightSetWaveform{Transient: true, Color: testColor, Period: 1000, Cycles: 2.5, SkewRatio: -16384, Waveform: LightWaveformPulse},
+			21,
+			"00" + "01" + testColorHex + "e8030000" + "00002040" + "00c0" + "04",
+		},
+		{
+			&LightState{Color: testColor, Power: 0xffff, Label: label},
+			52,
+			testColorHex + "0000" + "ffff" + "4b69746368656e" + strings.Repeat("00", 25) + strings.Repeat("00", 8),
+		},
+		{
+			&DeviceStateService{Service: DeviceServiceUDP, Port: 56700},
+			5,
+			"01" + "7cdd0000",
+		},
+		{zones, 66, zonesHex},
+		{tile, 517, tileHex},
+	}
+
+	for _, test := range tests {
+		name := TypeName(test.payload.Type())
+
+		t.Run(name, func(t *testing.T) {
+			golden, err := hex.DecodeString(test.golden)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(golden) != test.size {
+				t.Fatalf("golden payload has %d bytes, want %d", len(golden), test.size)
+			}
+
+			data, err := test.payload.MarshalBinary()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := hex.EncodeToString(data), hex.EncodeToString(golden); got != want {
+				t.Fatalf("encoded\n%s\nwant\n%s", got, want)
+			}
+
+			// MarshalTo overwrites whatever the buffer held
+			marshaler := test.payload.(interface{ MarshalTo([]byte) (int, error) })
+			buff := bytes.Repeat([]byte{0xff}, test.size+1)
+			n, err := marshaler.MarshalTo(buff)
+
+			if err != nil || n != test.size || !bytes.Equal(buff[:n], golden) {
+				t.Fatalf("MarshalTo wrote %d bytes %x, %v", n, buff[:n], err)
+			}
+
+			if _, err = marshaler.MarshalTo(buff[:test.size-1]); err != io.ErrShortBuffer {
+				t.Fatalf("got %v for a short buffer, want io.ErrShortBuffer", err)
+			}
+
+			decoded, _ := New(test.payload.Type())
+
+			if err := decoded.UnmarshalBinary(golden); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(decoded, test.payload) {
+				t.Fatalf("decoded %+v, want %+v", decoded, test.payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	// HeaderLength is the size of the frame, frame address and protocol
+	// header in bytes
+	HeaderLength = 36
+	// ProtocolNumber is the only protocol number used by LIFX devices
+	ProtocolNumber = 1024
+)
+
+// Header is the frame, frame address and protocol header preceding every
+// payload
+type Header struct {
+	// Frame
+	Size        uint16
+	Origin      uint8
+	Tagged      bool
+	Addressable bool
+	Protocol    uint16
+	Source      uint32
+
+	// Frame address
+	Target      uint64
+	AckRequired bool
+	ResRequired bool
+	Sequence    uint8
+
+	// Protocol header
+	Type uint16
+}
+
+// MarshalBinary encodes the header into HeaderLength bytes
+func (h *Header) MarshalBinary() ([]byte, error) {
+	data := make([]byte, HeaderLength)
+	h.encode(data)
+	return data, nil
+}
+
+// MarshalTo encodes the header into the first HeaderLength bytes of data
+func (h *Header) MarshalTo(data []byte) (int, error) {
+	if len(data) < HeaderLength {
+		return 0, io.ErrShortBuffer
+	}
+
+	data = data[:HeaderLength]
+
+	for i := range data {
+		data[i] = 0
+	}
+
+	h.encode(data)
+	return HeaderLength, nil
+}
+
+// UnmarshalBinary decodes the header from the first HeaderLength bytes of
+// data. Only the length is checked, use Validate to check the fields.
+func (h *Header) UnmarshalBinary(data []byte) error {
+	if len(data) < HeaderLength {
+		return &DecodeError{Length: len(data), Want: HeaderLength, Err: ErrShortHeader}
+	}
+
+	h.decode(data)
+	return nil
+}
+
+// Validate checks the size field against the length of the packet the
+// header was decoded from and the protocol number
+func (h *Header) Validate(length int) error {
+	if int(h.Size) != length || h.Size < HeaderLength {
+		return &DecodeError{Type: h.Type, Length: length, Want: int(h.Size), Err: ErrSizeMismatch}
+	}
+
+	if h.Protocol != ProtocolNumber {
+		return &DecodeError{Type: h.Type, Length: length, Err: ErrProtocolNumber}
+	}
+
+	return nil
+}
+
+func (h *Header) encode(data []byte) {
+	binary.LittleEndian.PutUint16(data[0:2], h.Size)
+
+	frame := h.Protocol & 0x0FFF
+	frame |= uint16(boolToUint8(h.Addressable)) << 12
+	frame |= uint16(boolToUint8(h.Tagged)) << 13
+	frame |= uint16(h.Origin&0x03) << 14
+	binary.LittleEndian.PutUint16(data[2:4], frame)
+
+	binary.LittleEndian.PutUint32(data[4:8], h.Source)
+	binary.LittleEndian.PutUint64(data[8:16], h.Target)
+	data[22] = boolToUint8(h.AckRequired)<<1 | boolToUint8(h.ResRequired)
+	data[23] = h.Sequence
+	binary.LittleEndian.PutUint16(data[32:34], h.Type)
+}
+
+func (h *Header) decode(data []byte) {
+	h.Size = binary.LittleEndian.Uint16(data[0:2])
+
+	frame := binary.LittleEndian.Uint16(data[2:4])
+	h.Protocol = frame & 0x0FFF
+	h.Addressable = frame>>12&1 == 1
+	h.Tagged = frame>>13&1 == 1
+	h.Origin = uint8(frame >> 14)
+
+	h.Source = binary.LittleEndian.Uint32(data[4:8])
+	h.Target = binary.LittleEndian.Uint64(data[8:16])
+	h.AckRequired = data[22]>>1&1 == 1
+	h.ResRequired = data[22]&1 == 1
+	h.Sequence = data[23]
+	h.Type = binary.LittleEndian.Uint16(data[32:34])
+}
+
+func boolToUint8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header Header
+		golden string
+	}{
+		{
+			// The SetColor example of the LIFX documentation
+			"broadcast",
+			Header{Size: 49, Tagged: true, Addressable: true, Protocol: ProtocolNumber, Type: TypeLightSetColor},
+			"3100" + "0034" + "00000000" + "0000000000000000" + "000000000000" + "00" + "00" + "0000000000000000" + "6600" + "0000",
+		},
+		{
+			"addressed",
+			Header{
+				Size: HeaderLength, Addressable: true, Protocol: ProtocolNumber, Source: 0x12345678,
+				Target: 0x010000d573d0, AckRequired: true, ResRequired: true, Sequence: 7, Type: TypeDeviceGetPower,
+			},
+			"2400" + "0014" + "78563412" + "d073d50000010000" + "000000000000" + "03" + "07" + "0000000000000000" + "1400" + "0000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.header.MarshalBinary()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := hex.EncodeToString(data); got != test.golden {
+				t.Fatalf("encoded\n%s\nwant\n%s", got, test.golden)
+			}
+
+			decoded := Header{}
+
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded != test.header {
+				t.Fatalf("decoded %+v, want %+v", decoded, test.header)
+			}
+
+			if _, err := test.header.MarshalTo(data[:HeaderLength-1]); err != io.ErrShortBuffer {
+				t.Fatalf("got %v for a short buffer, want io.ErrShortBuffer", err)
+			}
+		})
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	packet := &Packet{}
+
+	if err := packet.UnmarshalBinary(testPacket(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if packet.Source != 0x12345678 || packet.Target != 0x010000d573d0 || packet.Sequence != 7 || packet.Type != TypeLightState {
+		t.Fatalf("decoded header %+v", packet.Header)
+	}
+
+	state, ok := packet.Payload.(*LightState)
+
+	if !ok || state.Power != 65535 || state.Color.Hue != 21845 || string(state.Label[:7]) != "Kitchen" {
+		t.Fatalf("decoded payload %+v", packet.Payload)
+	}
+
+	encoded, err := packet.MarshalBinary()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again := &Packet{}
+
+	if err = again.UnmarshalBinary(encoded); err != nil || !reflect.DeepEqual(again, packet) {
+		t.Fatalf("re-encoded packet decodes to %+v, %v", again, err)
+	}
+}
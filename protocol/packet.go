@@ -0,0 +1,137 @@
+package protocol
+
+import (
+	"encoding"
+	"fmt"
+	"sync"
+)
+
+type (
+	// Payload is the message specific part of a packet
+	Payload interface {
+		encoding.BinaryMarshaler
+		encoding.BinaryUnmarshaler
+		// Type returns the message type number
+		Type() uint16
+	}
+
+	// Packet is a header and its decoded payload
+	Packet struct {
+		Header
+		Payload Payload
+	}
+
+	// Raw is the payload of message types missing from the registry
+	Raw struct {
+		MessageType uint16
+		Data        []byte
+	}
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[uint16]func() Payload{}
+)
+
+// Register makes New return payloads created by factory for the message
+// type, replacing any previous registration
+func Register(messageType uint16, factory func() Payload) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[messageType] = factory
+}
+
+// New returns an empty payload for the message type, false is returned for
+// types missing from the registry
+func New(messageType uint16) (Payload, bool) {
+	registryMu.RLock()
+	factory, ok := registry[messageType]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// TypeName returns the name of a message type, e.g. "LightSetColor"
+func TypeName(messageType uint16) string {
+	if name, ok := typeNames[messageType]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", messageType)
+}
+
+// NewPacket returns an addressable packet for the payload
+func NewPacket(payload Payload) *Packet {
+	return &Packet{
+		Header: Header{
+			Addressable: true,
+			Protocol:    ProtocolNumber,
+			Type:        payload.Type(),
+		},
+		Payload: payload,
+	}
+}
+
+// MarshalBinary encodes the packet. Size and Type of the header are taken
+// from the payload.
+func (p *Packet) MarshalBinary() ([]byte, error) {
+	var payload []byte
+
+	if p.Payload != nil {
+		var err error
+
+		if payload, err = p.Payload.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		p.Type = p.Payload.Type()
+	}
+
+	p.Size = uint16(HeaderLength + len(payload))
+
+	data := make([]byte, HeaderLength, int(p.Size))
+	p.Header.encode(data)
+
+	return append(data, payload...), nil
+}
+
+// UnmarshalBinary validates and decodes the header and the payload
+// registered for the message type, unknown types are decoded as Raw.
+// Invalid data never panics, a *DecodeError is returned instead.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	if err := p.Header.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	if err := p.Header.Validate(len(data)); err != nil {
+		return err
+	}
+
+	payload, ok := New(p.Type)
+
+	if !ok {
+		payload = &Raw{MessageType: p.Type}
+	}
+
+	if err := payload.UnmarshalBinary(data[HeaderLength:]); err != nil {
+		return err
+	}
+
+	p.Payload = payload
+	return nil
+}
+
+func (r *Raw) Type() uint16 {
+	return r.MessageType
+}
+
+func (r *Raw) MarshalBinary() ([]byte, error) {
+	return r.Data, nil
+}
+
+func (r *Raw) UnmarshalBinary(data []byte) error {
+	r.Data = append([]byte(nil), data...)
+	return nil
+}
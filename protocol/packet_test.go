@@ -0,0 +1,127 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// testPacket returns an encoded LightState packet
+func testPacket(t testing.TB) []byte {
+	state := &LightState{Power: 65535}
+	state.Color.Hue = 21845
+	state.Color.Kelvin = 3500
+	copy(state.Label[:], "Kitchen")
+
+	packet := NewPacket(state)
+	packet.Source = 0x12345678
+	packet.Target = 0x010000d573d0
+	packet.Sequence = 7
+
+	data, err := packet.MarshalBinary()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// truncate cuts the packet to n bytes and sets the size field to match, so
+// decoding gets past the header checks
+func truncate(data []byte, n int) []byte {
+	data = append([]byte(nil), data[:n]...)
+	binary.LittleEndian.PutUint16(data, uint16(n))
+	return data
+}
+
+func TestPacketUnmarshalInvalid(t *testing.T) {
+	light := testPacket(t)
+
+	wrongSize := append([]byte(nil), light...)
+	binary.LittleEndian.PutUint16(wrongSize, uint16(len(light)+1))
+
+	wrongProtocol := append([]byte(nil), light...)
+	binary.LittleEndian.PutUint16(wrongProtocol[2:], binary.LittleEndian.Uint16(wrongProtocol[2:])+1)
+
+	service, _ := NewPacket(&DeviceStateService{Service: DeviceServiceUDP, Port: 56700}).MarshalBinary()
+	state64, _ := NewPacket(&TileState64{}).MarshalBinary()
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrShortHeader},
+		{"short header", light[:20], ErrShortHeader},
+		{"wrong size", wrongSize, ErrSizeMismatch},
+		{"header only", truncate(light, HeaderLength), ErrShortPayload},
+		{"wrong protocol", wrongProtocol, ErrProtocolNumber},
+		{"truncated LightState", truncate(light, HeaderLength+20), ErrShortPayload},
+		{"truncated StateService", truncate(service, HeaderLength+3), ErrShortPayload},
+		{"truncated State64", truncate(state64, HeaderLength+300), ErrShortPayload},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := (&Packet{}).UnmarshalBinary(test.data)
+
+			var decodeErr *DecodeError
+
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("got %v, want *DecodeError", err)
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func FuzzPacketUnmarshal(f *testing.F) {
+	f.Add(testPacket(f))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packet := &Packet{}
+		err := packet.UnmarshalBinary(data)
+
+		var decodeErr *DecodeError
+
+		if err != nil && !errors.As(err, &decodeErr) {
+			t.Fatalf("got %T %v, want *DecodeError", err, err)
+		}
+
+		if err == nil {
+			encoded, err := packet.MarshalBinary()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := (&Packet{}).UnmarshalBinary(encoded); err != nil {
+				t.Fatalf("re-encoded packet does not decode: %v", err)
+			}
+		}
+
+		// Every registered payload has to cope with any data
+		if len(data) < HeaderLength {
+			return
+		}
+
+		registryMu.RLock()
+		factories := make([]func() Payload, 0, len(registry))
+		for _, factory := range registry {
+			factories = append(factories, factory)
+		}
+		registryMu.RUnlock()
+
+		for _, factory := range factories {
+			payload := factory()
+
+			if err := payload.UnmarshalBinary(data[HeaderLength:]); err != nil && !errors.As(err, &decodeErr) {
+				t.Fatalf("%s: got %T %v, want *DecodeError", TypeName(payload.Type()), err, err)
+			}
+		}
+	})
+}
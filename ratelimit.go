@@ -0,0 +1,181 @@
+package golifx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket limiting the messages sent. Messages over the
+// limit are queued, they wait for their turn.
+type RateLimit struct {
+	// Rate is the number of messages per second, zero means no limit
+	Rate float64
+	// Burst is the number of messages that can be sent at once before Rate
+	// applies, values below one mean one
+	Burst int
+	// LatestWins makes a SetColorState waiting for its turn give way to a
+	// newer SetColorState for the same bulb instead of queueing behind it,
+	// the older call returns ErrSuperseded. SetColorStateWithResponse and
+	// streams, which drop their own stale frames, are never superseded.
+	// Only used for bulbs.
+	LatestWins bool
+}
+
+type (
+	// rateLimiter holds a bucket per bulb and one for broadcasts
+	rateLimiter struct {
+		mu        sync.Mutex
+		bulb      RateLimit
+		broadcast *tokenBucket
+		buckets   map[uint64]*tokenBucket
+	}
+
+	tokenBucket struct {
+		mu     sync.Mutex
+		limit  RateLimit
+		tokens float64
+		last   time.Time
+		// waiting is closed to cancel the color change waiting for a token
+		waiting chan struct{}
+	}
+)
+
+var (
+	// DefaultRateLimit is the per bulb limit of clients created without
+	// WithRateLimit, bulbs drop messages above about 20 per second
+	DefaultRateLimit = RateLimit{Rate: 20, Burst: 5}
+	// DefaultBroadcastRateLimit is the broadcast limit of clients created
+	// without WithBroadcastRateLimit
+	DefaultBroadcastRateLimit = RateLimit{Rate: 20, Burst: 5}
+
+	// ErrSuperseded is returned for a color change replaced by a newer one
+	// before it was sent, see RateLimit.LatestWins
+	ErrSuperseded = errors.New("Superseded by a newer color change")
+)
+
+func newRateLimiter(bulb, broadcast RateLimit) *rateLimiter {
+	return &rateLimiter{
+		bulb:      bulb,
+		broadcast: newTokenBucket(broadcast),
+		buckets:   map[uint64]*tokenBucket{},
+	}
+}
+
+// wait blocks until a message may be sent to the bulb, latest marks a color
+// change that a newer one may supersede
+func (l *rateLimiter) wait(ctx context.Context, hardwareAddress uint64, latest bool) error {
+	l.mu.Lock()
+	bucket, ok := l.buckets[hardwareAddress]
+
+	if !ok {
+		bucket = newTokenBucket(l.bulb)
+		l.buckets[hardwareAddress] = bucket
+	}
+	l.mu.Unlock()
+
+	if latest && bucket.limit.LatestWins {
+		return bucket.waitLatest(ctx)
+	}
+
+	return bucket.wait(ctx, nil)
+}
+
+// waitBroadcast blocks until a broadcast may be sent
+func (l *rateLimiter) waitBroadcast(ctx context.Context) error {
+	return l.broadcast.wait(ctx, nil)
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	return &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is available.
+// Tokens go negative while messages are queued.
+func (t *tokenBucket) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+
+	t.tokens += now.Sub(t.last).Seconds() * t.limit.Rate
+	t.last = now
+
+	if burst := float64(t.limit.Burst); t.tokens > burst {
+		t.tokens = burst
+	}
+
+	t.tokens--
+
+	if t.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-t.tokens / t.limit.Rate * float64(time.Second))
+}
+
+// refund gives back the token of a message that was not sent
+func (t *tokenBucket) refund() {
+	t.mu.Lock()
+	t.tokens++
+	t.mu.Unlock()
+}
+
+// wait blocks until a token is available, ctx is done or cancel is closed
+func (t *tokenBucket) wait(ctx context.Context, cancel chan struct{}) error {
+	if t.limit.Rate <= 0 {
+		return nil
+	}
+
+	delay := t.reserve()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-cancel:
+		t.refund()
+		return ErrSuperseded
+	case <-ctx.Done():
+		t.refund()
+		return ctx.Err()
+	}
+}
+
+// waitLatest is wait for color changes, a newer color change cancels the
+// one waiting
+func (t *tokenBucket) waitLatest(ctx context.Context) error {
+	cancel := make(chan struct{})
+
+	t.mu.Lock()
+	if t.waiting != nil {
+		close(t.waiting)
+	}
+	t.waiting = cancel
+	t.mu.Unlock()
+
+	err := t.wait(ctx, cancel)
+
+	t.mu.Lock()
+	if t.waiting == cancel {
+		t.waiting = nil
+	}
+	t.mu.Unlock()
+
+	return err
+}
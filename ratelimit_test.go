@@ -0,0 +1,159 @@
+package golifx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurst(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 50, Burst: 3})
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := bucket.wait(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("burst took %s", elapsed)
+	}
+
+	// The fourth message waits for a token at 50 per second
+	if err := bucket.wait(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("message after the burst sent after %s, want 20ms", elapsed)
+	}
+}
+
+func TestTokenBucketRate(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 100})
+	start := time.Now()
+
+	// One token at once, then ten more at 100 per second
+	for i := 0; i < 11; i++ {
+		if err := bucket.wait(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond || elapsed > 300*time.Millisecond {
+		t.Fatalf("11 messages took %s, want 100ms", elapsed)
+	}
+
+	// Without a rate nothing waits
+	unlimited := newTokenBucket(RateLimit{})
+	start = time.Now()
+
+	for i := 0; i < 1000; i++ {
+		unlimited.wait(context.Background(), nil)
+	}
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("unlimited messages took %s", elapsed)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Rate: 10})
+	bucket.wait(context.Background(), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := bucket.wait(ctx, nil); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+
+	// The token of the cancelled message is given back
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+
+	if tokens < -0.5 {
+		t.Fatalf("%g tokens left, the cancelled message kept its token", tokens)
+	}
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{Rate: 10}, RateLimit{Rate: 10})
+	start := time.Now()
+
+	// Every bulb has a bucket of its own
+	for mac := uint64(1); mac <= 3; mac++ {
+		if err := limiter.wait(context.Background(), mac, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := limiter.waitBroadcast(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("first messages took %s", elapsed)
+	}
+
+	// Broadcasts share one bucket
+	if err := limiter.waitBroadcast(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("second broadcast sent after %s, want 100ms", elapsed)
+	}
+}
+
+func TestLatestWins(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{Rate: 20, LatestWins: true}, RateLimit{})
+	limiter.wait(context.Background(), 1, false)
+
+	wait := func(latest bool) chan error {
+		result := make(chan error, 1)
+
+		go func() {
+			result <- limiter.wait(context.Background(), 1, latest)
+		}()
+
+		// Let the message take its place in the queue
+		time.Sleep(5 * time.Millisecond)
+		return result
+	}
+
+	older := wait(true)
+	frame := wait(false)
+	newer := wait(true)
+
+	if err := <-older; err != ErrSuperseded {
+		t.Fatalf("older color change got %v, want ErrSuperseded", err)
+	}
+
+	// Messages that are not latest wins, e.g. stream frames, are sent
+	if err := <-frame; err != nil {
+		t.Fatalf("frame got %v", err)
+	}
+
+	if err := <-newer; err != nil {
+		t.Fatalf("newer color change got %v", err)
+	}
+
+	// Neither frames nor color changes of another bulb supersede one
+	pending := wait(true)
+	frame = wait(false)
+
+	if err := limiter.wait(context.Background(), 2, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-pending; err != nil {
+		t.Fatalf("color change got %v", err)
+	}
+
+	if err := <-frame; err != nil {
+		t.Fatalf("frame got %v", err)
+	}
+}
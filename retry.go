@@ -0,0 +1,150 @@
+package golifx
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how requests expecting an acknowledgement or a
+// response are resent when nothing comes back. Every attempt resends the
+// same message with the same sequence number, so a late reply to an earlier
+// attempt completes the request as well.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the message is sent to the bulb
+	// address, values below one mean one. When all of them fail the
+	// message is broadcast once more, so a bulb with a known address gets
+	// MaxAttempts+1 sends. Bulbs without a known address only get the
+	// broadcast.
+	MaxAttempts int
+	// Timeout is how long a single send waits for a reply. Zero shares the
+	// client deadline between all the sends and the pauses before them, so
+	// a request to a bulb that is gone fails within the deadline.
+	Timeout time.Duration
+	// Backoff is the pause before the second attempt, it doubles for every
+	// following attempt
+	Backoff time.Duration
+	// MaxBackoff caps the pause between attempts, zero means no cap
+	MaxBackoff time.Duration
+	// Jitter randomly shortens every pause by up to this fraction (0..1)
+	Jitter float64
+}
+
+type retryPolicyKey struct{}
+
+var (
+	// DefaultRetryPolicy is used by clients created without WithRetryPolicy
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond * 50,
+		MaxBackoff:  time.Second,
+		Jitter:      0.2,
+	}
+)
+
+// ContextWithRetryPolicy returns a context overriding the client retry
+// policy for calls made with it
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) timeout(deadline time.Duration) time.Duration {
+	if p.Timeout <= 0 {
+		return deadline
+	}
+	return p.Timeout
+}
+
+// sendTimeout returns how long each of the sends of a request waits for a
+// reply. Without a Timeout the deadline left after the pauses is shared
+// between the sends, when the pauses do not fit the deadline cuts the last
+// sends short.
+func (p RetryPolicy) sendTimeout(deadline time.Duration, sends int) time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+
+	budget := deadline
+
+	for attempt := 1; attempt < sends; attempt++ {
+		budget -= p.fullBackoff(attempt)
+	}
+
+	if budget <= 0 {
+		budget = deadline
+	}
+
+	return budget / time.Duration(sends)
+}
+
+// backoff returns the pause before the given attempt, counting from zero
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.fullBackoff(attempt)
+
+	if delay > 0 && p.Jitter > 0 {
+		jitter := p.Jitter
+
+		if jitter > 1 {
+			jitter = 1
+		}
+
+		delay -= time.Duration(float64(delay) * jitter * rand.Float64())
+	}
+
+	return delay
+}
+
+// fullBackoff returns the pause before the given attempt without jitter
+func (p RetryPolicy) fullBackoff(attempt int) time.Duration {
+	if attempt < 1 || p.Backoff <= 0 {
+		return 0
+	}
+
+	delay := p.Backoff
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	return delay
+}
+
+// retryPolicyFor returns the policy for a call, preferring the one carried by ctx
+func (c *Client) retryPolicyFor(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return c.retryPolicy
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,74 @@
+package golifx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	want := []time.Duration{0, 10, 20, 40, 50, 50}
+
+	for attempt, delay := range want {
+		if got := policy.backoff(attempt); got != delay*time.Millisecond {
+			t.Errorf("attempt %d: got %s, want %s", attempt, got, delay*time.Millisecond)
+		}
+	}
+
+	// Doubling stops at the cap instead of overflowing
+	if got := policy.backoff(100); got != policy.MaxBackoff {
+		t.Errorf("attempt 100: got %s, want %s", got, policy.MaxBackoff)
+	}
+
+	policy.MaxBackoff = 0
+
+	if got := policy.backoff(4); got != 80*time.Millisecond {
+		t.Errorf("uncapped attempt 4: got %s", got)
+	}
+
+	if got := (RetryPolicy{}).backoff(3); got != 0 {
+		t.Errorf("no backoff: got %s", got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	for _, jitter := range []float64{0.2, 1, 5} {
+		policy := RetryPolicy{Backoff: 100 * time.Millisecond, Jitter: jitter}
+		min := 100*time.Millisecond - time.Duration(float64(100*time.Millisecond)*jitter)
+
+		if jitter > 1 {
+			min = 0
+		}
+
+		for i := 0; i < 100; i++ {
+			if got := policy.backoff(1); got < min || got > 100*time.Millisecond {
+				t.Fatalf("jitter %g: got %s, want %s to 100ms", jitter, got, min)
+			}
+		}
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	tests := []struct {
+		policy   RetryPolicy
+		deadline time.Duration
+		sends    int
+		want     time.Duration
+	}{
+		{RetryPolicy{Timeout: 30 * time.Millisecond}, time.Second, 4, 30 * time.Millisecond},
+		// The deadline is shared between the sends
+		{RetryPolicy{}, 400 * time.Millisecond, 4, 100 * time.Millisecond},
+		// after the pauses of 50, 100 and 200ms
+		{DefaultRetryPolicy, 550 * time.Millisecond, 4, 50 * time.Millisecond},
+		// Pauses longer than the deadline leave the sends the whole of it
+		{DefaultRetryPolicy, 200 * time.Millisecond, 4, 50 * time.Millisecond},
+		{RetryPolicy{}, 500 * time.Millisecond, 1, 500 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		if got := test.policy.sendTimeout(test.deadline, test.sends); got != test.want {
+			t.Errorf("%+v over %s: got %s, want %s", test.policy, test.deadline, got, test.want)
+		}
+	}
+}
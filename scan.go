@@ -0,0 +1,156 @@
+package golifx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// ScanOptions configures Scan
+type ScanOptions struct {
+	// Rate is the maximum number of GetService messages sent per second,
+	// zero means 200
+	Rate int
+	// Wait is how long to listen for replies after the last message was
+	// sent, zero means the client deadline
+	Wait time.Duration
+}
+
+const _DEFAULT_SCAN_RATE = 200
+
+// ErrNotIPv4Network is returned by Scan for networks other than IPv4
+var ErrNotIPv4Network = errors.New("Only IPv4 networks can be scanned")
+
+// Scan sends GetService unicast to every host address of the networks and
+// returns the bulbs that answered. It finds bulbs on networks filtering
+// broadcast traffic, where LookupBulbs finds nothing. Messages go out
+// through the interface whose subnet contains the address.
+func (c *Client) Scan(ctx context.Context, networks []*net.IPNet, options ScanOptions) ([]*Bulb, error) {
+	for _, network := range networks {
+		if network.IP.To4() == nil {
+			return nil, ErrNotIPv4Network
+		}
+	}
+
+	rate := options.Rate
+
+	if rate <= 0 {
+		rate = _DEFAULT_SCAN_RATE
+	}
+
+	wait := options.Wait
+
+	if wait <= 0 {
+		wait = c.deadline
+	}
+
+	msg := c.makeMessage()
+	msg.tagged = true
+	msg._type = protocol.TypeDeviceGetService
+
+	req, err := c.conn.register(msg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.conn.unregister(msg, req)
+
+	raw := msg.ReadRaw()
+	interval := time.Second / time.Duration(rate)
+
+	// Rates above one per nanosecond are as fast as the ticker goes
+	if interval <= 0 {
+		interval = 1
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Writes do not block, a single goroutine keeps up with the rate
+	sent := make(chan struct{})
+
+	go func() {
+		defer close(sent)
+
+		for _, network := range networks {
+			first, last := hostRange(network)
+
+			for n := uint64(first); n <= uint64(last); n++ {
+				ip := net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).To4()
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+
+				c.conn.write(c.conn.linkFor(ip), raw, &net.UDPAddr{IP: ip, Port: c.conn.port})
+			}
+		}
+	}()
+
+	bulbs := []*Bulb{}
+	seen := map[uint64]bool{}
+
+	var timeout <-chan time.Time
+
+	for {
+		select {
+		case m, ok := <-req.responses:
+			if !ok {
+				return bulbs, ErrConnectionClosed
+			}
+
+			bulb := c.bulbFromService(m)
+
+			if bulb == nil || seen[bulb.hardwareAddress] {
+				continue
+			}
+
+			seen[bulb.hardwareAddress] = true
+			bulbs = append(bulbs, c.addBulb(bulb))
+		case <-sent:
+			sent = nil
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			timeout = timer.C
+		case <-timeout:
+			return bulbs, nil
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return bulbs, nil
+			}
+			return bulbs, ctx.Err()
+		}
+	}
+}
+
+// hostRange returns the first and last host address of an IPv4 network,
+// leaving out the network and broadcast addresses of subnets larger than /31
+func hostRange(network *net.IPNet) (uint32, uint32) {
+	ip := network.IP.To4()
+	mask := network.Mask
+
+	if len(mask) == net.IPv6len {
+		mask = mask[12:]
+	}
+
+	ones, _ := mask.Size()
+	first := readIPv4(ip.Mask(mask))
+	last := first | uint32(uint64(1)<<uint(32-ones)-1)
+
+	if ones < 31 {
+		first++
+		last--
+	}
+
+	return first, last
+}
+
+func readIPv4(ip net.IP) uint32 {
+	return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+}
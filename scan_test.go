@@ -0,0 +1,85 @@
+package golifx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestHostRange(t *testing.T) {
+	tests := []struct {
+		network     *net.IPNet
+		first, last string
+	}{
+		{parseCIDR(t, "192.168.1.7/24"), "192.168.1.1", "192.168.1.254"},
+		{parseCIDR(t, "10.0.0.4/30"), "10.0.0.5", "10.0.0.6"},
+		// Point to point links have no network and broadcast address
+		{parseCIDR(t, "10.0.0.4/31"), "10.0.0.4", "10.0.0.5"},
+		{parseCIDR(t, "10.0.0.4/32"), "10.0.0.4", "10.0.0.4"},
+		{parseCIDR(t, "0.0.0.0/0"), "0.0.0.1", "255.255.255.254"},
+		// IPv4 addresses with an IPv6 mask
+		{&net.IPNet{IP: net.IPv4(172, 16, 0, 9), Mask: net.CIDRMask(120, 128)}, "172.16.0.1", "172.16.0.254"},
+	}
+
+	for _, test := range tests {
+		first, last := hostRange(test.network)
+
+		if got := ipv4(first).String(); got != test.first {
+			t.Errorf("%s: first %s, want %s", test.network, got, test.first)
+		}
+
+		if got := ipv4(last).String(); got != test.last {
+			t.Errorf("%s: last %s, want %s", test.network, got, test.last)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	useInterfaceNets(t, "127.0.0.1/8")
+
+	d, addr := listenTestDevice(t)
+
+	client := NewClient(WithPort(addr.Port))
+	defer client.Close()
+
+	bulbs, err := client.Scan(context.Background(), []*net.IPNet{parseCIDR(t, "127.0.0.0/30")}, ScanOptions{
+		Rate: 1000,
+		Wait: 100 * time.Millisecond,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The device answered through the loopback interface link
+	if len(bulbs) != 1 || bulbs[0].HardwareAddress() != d.mac || bulbs[0].Interface() != "lo" {
+		t.Fatalf("got %v", bulbs)
+	}
+
+	if known := client.Bulbs(); len(known) != 1 || known[0] != bulbs[0] {
+		t.Fatalf("client knows %v", known)
+	}
+
+	_, ipv6, _ := net.ParseCIDR("fe80::/64")
+
+	if _, err := client.Scan(context.Background(), []*net.IPNet{ipv6}, ScanOptions{}); err != ErrNotIPv4Network {
+		t.Fatalf("got %v, want ErrNotIPv4Network", err)
+	}
+}
+
+// parseCIDR returns the network of an address in CIDR notation, keeping the
+// host part of the address
+func parseCIDR(t *testing.T, s string) *net.IPNet {
+	ip, network, err := net.ParseCIDR(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &net.IPNet{IP: ip, Mask: network.Mask}
+}
+
+func ipv4(n uint32) net.IP {
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).To4()
+}
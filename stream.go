@@ -0,0 +1,267 @@
+package golifx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+type (
+	// StreamOptions configures Bulb.Stream
+	StreamOptions struct {
+		// SampleEvery asks for an acknowledgement of one frame out of
+		// SampleEvery to measure the loss, zero means 30 and a negative
+		// value turns sampling off
+		SampleEvery int
+		// ReportInterval is how often Report is called, zero means every
+		// second
+		ReportInterval time.Duration
+		// Report receives the statistics of the stream, it may be nil
+		Report func(StreamStats)
+	}
+
+	// StreamStats counts the frames of a stream
+	StreamStats struct {
+		// Sent is the number of frames written to the network
+		Sent uint64
+		// Dropped is the number of frames replaced by a newer one before
+		// they were written
+		Dropped uint64
+		// Sampled is the number of frames sent with an acknowledgement
+		// request whose outcome is known
+		Sampled uint64
+		// Acked is the number of sampled frames acknowledged by the bulb
+		Acked uint64
+	}
+
+	// Stream sends colors to a bulb without waiting for any reply. Only the
+	// latest color is kept, frames the network or the rate limit cannot
+	// keep up with are dropped.
+	Stream struct {
+		bulb    *Bulb
+		client  *Client
+		options StreamOptions
+		header  protocol.Header
+		payload protocol.LightSetColor
+		buff    []byte
+
+		mu      sync.Mutex
+		pending *protocol.LightSetColor
+		stats   StreamStats
+
+		ready  chan struct{}
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+)
+
+const (
+	_DEFAULT_STREAM_SAMPLE_EVERY = 30
+	_DEFAULT_STREAM_REPORT       = time.Second
+)
+
+// Loss returns the fraction of sampled frames that were not acknowledged
+func (s StreamStats) Loss() float64 {
+	if s.Sampled == 0 {
+		return 0
+	}
+	return float64(s.Sampled-s.Acked) / float64(s.Sampled)
+}
+
+// Stream returns a stream of SetColor messages to the bulb. The stream is
+// closed by Close or when ctx is done.
+func (b *Bulb) Stream(ctx context.Context, options StreamOptions) *Stream {
+	if options.SampleEvery == 0 {
+		options.SampleEvery = _DEFAULT_STREAM_SAMPLE_EVERY
+	}
+
+	if options.ReportInterval <= 0 {
+		options.ReportInterval = _DEFAULT_STREAM_REPORT
+	}
+
+	client := b.getClient()
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &Stream{
+		bulb:    b,
+		client:  client,
+		options: options,
+		header: protocol.Header{
+			Size:        uint16(protocol.HeaderLength + (&protocol.LightSetColor{}).Size()),
+			Addressable: true,
+			Protocol:    protocol.ProtocolNumber,
+			Source:      client.source,
+			Target:      b.hardwareAddress,
+			Type:        protocol.TypeLightSetColor,
+		},
+		ready:  make(chan struct{}, 1),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	s.buff = make([]byte, s.header.Size)
+
+	go s.run(ctx)
+
+	return s
+}
+
+// SetColor queues the color for sending and returns at once. A color still
+// waiting to be sent is replaced.
+func (s *Stream) SetColor(hsbk HSBK, duration uint32) {
+	s.mu.Lock()
+	if s.pending != nil {
+		s.stats.Dropped++
+	}
+
+	s.pending = &protocol.LightSetColor{
+		Color:    *hsbk.toProtocol(),
+		Duration: duration,
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Stats returns the statistics of the stream so far
+func (s *Stream) Stats() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
+
+// Close stops the stream, a color waiting to be sent is dropped
+func (s *Stream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+func (s *Stream) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.ReportInterval)
+	defer ticker.Stop()
+
+	var sent int
+
+	for {
+		select {
+		case <-s.ready:
+		case <-ticker.C:
+			if s.options.Report != nil {
+				s.options.Report(s.Stats())
+			}
+			continue
+		case <-ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		waiting := s.pending != nil
+		s.mu.Unlock()
+
+		if !waiting {
+			continue
+		}
+
+		err := s.client.limiter.wait(ctx, s.bulb.hardwareAddress, false)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// The frame is taken after waiting for the rate limit, so frames
+		// arriving meanwhile replace it
+		s.mu.Lock()
+		payload := s.pending
+		s.pending = nil
+
+		if err != nil && payload != nil {
+			s.stats.Dropped++
+			payload = nil
+		}
+		s.mu.Unlock()
+
+		if payload == nil {
+			continue
+		}
+
+		sample := s.options.SampleEvery > 0 && sent%s.options.SampleEvery == 0
+		sent++
+
+		if s.send(ctx, payload, sample) == nil {
+			s.mu.Lock()
+			s.stats.Sent++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// send writes the frame into the reused buffer and sends it, a sampled frame
+// asks for an acknowledgement which is waited for in the background
+func (s *Stream) send(ctx context.Context, payload *protocol.LightSetColor, sample bool) error {
+	var req *request
+
+	s.header.AckRequired = sample
+	s.header.Sequence = 0
+
+	if sample {
+		msg := s.client.makeMessage()
+		msg.target = s.header.Target
+		msg._type = protocol.TypeLightSetColor
+		msg.ack_required = true
+
+		var err error
+
+		if req, err = s.client.conn.register(msg); err != nil {
+			return err
+		}
+
+		s.header.Sequence = msg.sequence
+
+		defer func() {
+			go s.waitAck(ctx, msg, req)
+		}()
+	}
+
+	s.header.MarshalTo(s.buff)
+	payload.MarshalTo(s.buff[protocol.HeaderLength:])
+
+	link := s.bulb.linkKey()
+	addr := s.bulb.udpAddr()
+
+	if addr == nil {
+		addr = s.client.conn.broadcastAddrFor(link)
+	}
+
+	return s.client.conn.write(link, s.buff, addr)
+}
+
+func (s *Stream) waitAck(ctx context.Context, msg *message, req *request) {
+	defer s.client.conn.unregister(msg, req)
+
+	ctx, cancel := context.WithTimeout(ctx, s.client.deadline)
+	defer cancel()
+
+	response, err := s.client.conn.wait(ctx, req)
+
+	// Samples cut short by closing the stream are not counted
+	if err == ErrTimeout && ctx.Err() == context.Canceled {
+		return
+	}
+
+	s.mu.Lock()
+	s.stats.Sampled++
+
+	if err == nil && response._type == protocol.TypeDeviceAcknowledgement {
+		s.stats.Acked++
+	}
+	s.mu.Unlock()
+}
@@ -0,0 +1,171 @@
+package golifx
+
+import (
+	"context"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+type (
+	// BulbEventType tells which bulb state changed
+	BulbEventType int
+
+	// BulbEvent is emitted by Subscribe when a bulb reports a new state.
+	// Only the field matching Type is set.
+	BulbEvent struct {
+		Type  BulbEventType
+		Bulb  *Bulb
+		Power bool
+		Color HSBK
+		Label string
+	}
+)
+
+const (
+	// PowerChanged is emitted when a bulb is switched on or off
+	PowerChanged BulbEventType = iota
+	// ColorChanged is emitted when the color of a bulb changes
+	ColorChanged
+	// LabelChanged is emitted when a bulb is renamed
+	LabelChanged
+)
+
+func (t BulbEventType) String() string {
+	switch t {
+	case PowerChanged:
+		return "power"
+	case ColorChanged:
+		return "color"
+	case LabelChanged:
+		return "label"
+	}
+	return "unknown"
+}
+
+// Subscribe listens for state packets bulbs send when they are changed
+// elsewhere (by the LIFX app, a switch or another client) and emits an
+// event for every change of power, color or label. Packets are received on
+// the bulb port and on the client sockets, an error is returned when
+// another program holds the port, see WithPortListener. The cached state of
+// the client bulbs is updated, bulbs not known yet are added. The channel
+// is closed when ctx is done.
+func (c *Client) Subscribe(ctx context.Context) (<-chan BulbEvent, error) {
+	messages, err := c.conn.subscribe()
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan BulbEvent)
+
+	go func() {
+		defer close(events)
+		defer c.conn.unsubscribe(messages)
+
+		for {
+			select {
+			case m := <-messages:
+				// Late replies to requests of the client are not changes
+				if m.source == c.source {
+					continue
+				}
+
+				for _, event := range c.stateEvents(m) {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// stateEvents updates the bulb that sent a state packet and returns what
+// changed, other packets are ignored
+func (c *Client) stateEvents(m *message) []BulbEvent {
+	var power, color, label *BulbEvent
+
+	switch m._type {
+	case protocol.TypeLightState:
+		state := &protocol.LightState{}
+
+		if state.UnmarshalBinary(m.payout) != nil {
+			return nil
+		}
+
+		power = &BulbEvent{Type: PowerChanged, Power: state.Power != 0}
+		color = &BulbEvent{Type: ColorChanged, Color: hsbkFromProtocol(state.Color)}
+		label = &BulbEvent{Type: LabelChanged, Label: trimString(state.Label[:])}
+	case protocol.TypeDeviceStatePower:
+		state := &protocol.DeviceStatePower{}
+
+		if state.UnmarshalBinary(m.payout) != nil {
+			return nil
+		}
+
+		power = &BulbEvent{Type: PowerChanged, Power: state.Level != 0}
+	case protocol.TypeLightStatePower:
+		state := &protocol.LightStatePower{}
+
+		if state.UnmarshalBinary(m.payout) != nil {
+			return nil
+		}
+
+		power = &BulbEvent{Type: PowerChanged, Power: state.Level != 0}
+	case protocol.TypeDeviceStateLabel:
+		state := &protocol.DeviceStateLabel{}
+
+		if state.UnmarshalBinary(m.payout) != nil {
+			return nil
+		}
+
+		label = &BulbEvent{Type: LabelChanged, Label: trimString(state.Label[:])}
+	default:
+		return nil
+	}
+
+	if m.target == 0 {
+		return nil
+	}
+
+	bulb := c.addBulb(&Bulb{
+		client:          c,
+		hardwareAddress: m.target,
+		ipAddress:       m.addr,
+		iface:           m.iface,
+		link:            m.link,
+	})
+
+	events := []BulbEvent{}
+
+	bulb.lock()
+	defer bulb.unlock()
+
+	if power != nil && power.Power != bulb.powerState {
+		bulb.powerState = power.Power
+		events = append(events, *power)
+	}
+
+	if color != nil && (bulb.color == nil || color.Color != *bulb.color) {
+		hsbk := color.Color
+		bulb.color = &hsbk
+		events = append(events, *color)
+	}
+
+	if label != nil && label.Label != bulb.label {
+		bulb.label = label.Label
+		events = append(events, *label)
+	}
+
+	for i := range events {
+		events[i].Bulb = bulb
+	}
+
+	return events
+}
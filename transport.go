@@ -0,0 +1,76 @@
+package golifx
+
+import (
+	"net"
+)
+
+type (
+	// Transport sends and receives LIFX packets. *net.UDPConn implements it,
+	// WithTransport replaces the client socket with another implementation
+	// such as MemoryTransport.
+	Transport interface {
+		// WriteTo sends a packet to addr
+		WriteTo(p []byte, addr net.Addr) (int, error)
+		// ReadFrom blocks until a packet arrives and copies it into p.
+		// Errors other than timeouts stop the client from reading.
+		ReadFrom(p []byte) (int, net.Addr, error)
+		// Close unblocks ReadFrom and releases the transport
+		Close() error
+		// LocalAddr returns the address packets are sent from
+		LocalAddr() net.Addr
+	}
+
+	// Middleware wraps a transport, e.g. to log packets, count them or
+	// inject faults. See WithMiddleware.
+	Middleware func(Transport) Transport
+)
+
+// NewUDPTransport returns a transport bound to the local address, nil means
+// any address and a random port
+func NewUDPTransport(addr *net.UDPAddr) (Transport, error) {
+	udpConn, err := net.ListenUDP("udp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return udpConn, nil
+}
+
+// WithTransport makes the client send and receive through the transport
+// instead of its own UDP socket, it turns interface discovery off. The
+// transport is closed by Client.Close.
+func WithTransport(transport Transport) ClientOption {
+	return func(c *Client) {
+		c.conn.transport = transport
+		c.conn.interfaces = false
+	}
+}
+
+// WithMiddleware wraps every transport of the client, the default UDP
+// sockets as well as one set by WithTransport. The first middleware is the
+// outermost.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.conn.middleware = append(c.conn.middleware, middleware...)
+	}
+}
+
+// listen binds a UDP transport to addr
+func (c *connection) listen(addr *net.UDPAddr) (Transport, error) {
+	transport, err := NewUDPTransport(addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.wrap(transport), nil
+}
+
+// wrap applies the middleware to the transport
+func (c *connection) wrap(transport Transport) Transport {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+	return transport
+}
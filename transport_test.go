@@ -0,0 +1,197 @@
+package golifx
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2tvenom/golifx/protocol"
+)
+
+// dropWrites is a fault injecting transport losing the first drop packets
+// it sends, every packet when drop is negative. It records where packets
+// were sent to.
+type dropWrites struct {
+	Transport
+
+	mu     sync.Mutex
+	drop   int
+	writes int
+	to     []string
+}
+
+func (d *dropWrites) WriteTo(p []byte, addr net.Addr) (int, error) {
+	d.mu.Lock()
+	d.writes++
+	d.to = append(d.to, addr.String())
+	lost := d.drop < 0 || d.writes <= d.drop
+	d.mu.Unlock()
+
+	if lost {
+		return len(p), nil
+	}
+	return d.Transport.WriteTo(p, addr)
+}
+
+func (d *dropWrites) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.writes
+}
+
+// sentTo returns the destinations of the packets sent so far
+func (d *dropWrites) sentTo() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.to...)
+}
+
+// memoryTestDevice starts a device at 10.0.0.2 on a fresh memory network
+// and returns a transport of the network for the client
+func memoryTestDevice(t *testing.T) (*testDevice, Transport) {
+	network := NewMemoryNetwork()
+
+	transport, err := network.Listen(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 56700})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDevice(t, transport)
+
+	clientTransport, err := network.Listen(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d, clientTransport
+}
+
+func TestMemoryTransport(t *testing.T) {
+	d, transport := memoryTestDevice(t)
+
+	client := NewClient(WithTransport(transport), WithDeadline(100*time.Millisecond))
+	defer client.Close()
+
+	bulbs, err := client.LookupBulbs()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bulbs) != 1 || bulbs[0].MacAddress() != "d0:73:d5:00:00:01" {
+		t.Fatalf("got %v, want the device", bulbs)
+	}
+
+	if want := "10.0.0.2:56700"; bulbs[0].udpAddr().String() != want {
+		t.Fatalf("got %s, want %s", bulbs[0].udpAddr(), want)
+	}
+
+	if err = bulbs[0].SetPowerState(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.powerLevel() != 65535 || d.count(protocol.TypeDeviceSetPower) != 1 {
+		t.Fatalf("device got %d sets, power %d", d.count(protocol.TypeDeviceSetPower), d.powerLevel())
+	}
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Timeout: 20 * time.Millisecond, Backoff: time.Millisecond}
+
+	tests := []struct {
+		drop     int
+		writes   int
+		received int
+	}{
+		// The first set is lost and sent again
+		{1, 2, 1},
+		// Three sends to the bulb and a broadcast are lost
+		{-1, 4, 0},
+	}
+
+	for _, test := range tests {
+		d, transport := memoryTestDevice(t)
+		faults := &dropWrites{drop: test.drop}
+
+		client := NewClient(
+			WithTransport(transport),
+			WithRetryPolicy(policy),
+			WithMiddleware(func(transport Transport) Transport {
+				faults.Transport = transport
+				return faults
+			}),
+		)
+
+		bulb := client.NewBulb(net.IPv4(10, 0, 0, 2), d.mac)
+		err := bulb.SetPowerState(true)
+
+		if test.drop < 0 {
+			var bulbErr *Error
+
+			if !errors.As(err, &bulbErr) || bulbErr.Attempts != test.writes || !errors.Is(err, ErrNoResponse) {
+				t.Errorf("drop %d: got %v, want no response after %d attempts", test.drop, err, test.writes)
+			}
+		} else if err != nil {
+			t.Errorf("drop %d: %s", test.drop, err)
+		}
+
+		if faults.count() != test.writes || d.count(protocol.TypeDeviceSetPower) != test.received {
+			t.Errorf("drop %d: %d writes, %d received, want %d and %d",
+				test.drop, faults.count(), d.count(protocol.TypeDeviceSetPower), test.writes, test.received)
+		}
+
+		client.Close()
+	}
+}
+
+func TestUnicast(t *testing.T) {
+	d, transport := memoryTestDevice(t)
+	recorder := &dropWrites{}
+
+	client := NewClient(
+		WithTransport(transport),
+		WithDeadline(100*time.Millisecond),
+		WithMiddleware(func(transport Transport) Transport {
+			recorder.Transport = transport
+			return recorder
+		}),
+	)
+	defer client.Close()
+
+	bulbs, err := client.LookupBulbs()
+
+	if err != nil || len(bulbs) != 1 {
+		t.Fatalf("got %v, %v, want the device", bulbs, err)
+	}
+
+	lookups := len(recorder.sentTo())
+
+	if lookups == 0 || recorder.sentTo()[0] != "255.255.255.255:56700" {
+		t.Fatalf("lookup sent to %v, want a broadcast", recorder.sentTo())
+	}
+
+	if err = bulbs[0].SetPowerState(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bulbs[0].GetPowerState(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the bulb gets commands once its address is known
+	sent := recorder.sentTo()[lookups:]
+
+	if len(sent) != 2 || sent[0] != "10.0.0.2:56700" || sent[1] != "10.0.0.2:56700" {
+		t.Fatalf("commands sent to %v, want 10.0.0.2:56700", sent)
+	}
+
+	if d.count(protocol.TypeDeviceSetPower) != 1 || d.count(protocol.TypeDeviceGetPower) != 1 {
+		t.Fatal("device did not get the commands")
+	}
+}